@@ -6,12 +6,17 @@ require github.com/joho/godotenv v1.5.1
 
 require (
 	github.com/AzureAD/microsoft-authentication-library-for-go v1.2.0
-	google.golang.org/protobuf v1.33.0
+	github.com/parquet-go/parquet-go v0.25.1
+	google.golang.org/protobuf v1.34.2
 )
 
 require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
 	github.com/golang-jwt/jwt/v5 v5.2.2 // indirect
-	github.com/google/uuid v1.3.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	golang.org/x/sys v0.21.0 // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 )
@@ -13,16 +13,6 @@ import (
 	"github.com/joho/godotenv"
 )
 
-// Find a unit by external ID
-func findUnitByExternalId(unitList *dto.UnitList, externalId string) (*dto.Unit, error) {
-	for _, unit := range unitList.Items {
-		if unit.ExternalId == externalId {
-			return &unit, nil
-		}
-	}
-	return nil, fmt.Errorf("unit not found with ExternalId: %s", externalId)
-}
-
 func main() {
 	// Load environment variables
 	err := godotenv.Load(".env")
@@ -75,12 +65,19 @@ func main() {
 
 	// Fetch the unit catalog
 	fmt.Println("\n### Testing fetching the Unit catalog")
-	unitList, err := client.Units.List()
+	unitCatalog := api.NewUnitCatalog(&client.Units, api.UnitCatalogConfig{})
+	unitList, err := unitCatalog.Units()
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
 	}
 	fmt.Println("Unit Count:", len(unitList.Items))
+	pressureUnits, err := unitCatalog.ByQuantity("Pressure")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("Pressure Unit Count:", len(pressureUnits))
 
 	// Fetch the latest data points
 	fmt.Println("\n### Latest Data Points")
@@ -110,13 +107,12 @@ func main() {
 			ExternalId: "EVE-TI-FORNEBU-01-2",
 			Start:      "300d-ago",
 			End:        "now",
-			Limit:      100000,
 		},
 	}
 	start := time.Now()
-	dataPoints, err := client.TimeSeries.RetrieveData(
+	dataPoints, err := client.TimeSeries.RetrieveAllConcurrently(
 		&items,
-		nil, nil, nil, nil, nil, nil, nil, nil,
+		api.FetchPlannerConfig{Concurrency: 8},
 	)
 	elapsed := time.Since(start)
 	if err != nil {
@@ -126,17 +122,7 @@ func main() {
 	dps := dataPoints.Items[0]
 	fmt.Println("Data Points External ID:", dps.ExternalId)
 	fmt.Println("Data Points Unit:", dps.UnitExternalId)
-	// not sure if there is a simpler way to get to the data points :)
-	switch v := dps.DatapointType.(type) {
-	case *dto.DataPointListItem_NumericDatapoints:
-		fmt.Println("Data Points Count:", len(v.NumericDatapoints.Datapoints))
-	case *dto.DataPointListItem_StringDatapoints:
-		fmt.Println("Data Points Count:", len(v.StringDatapoints.Datapoints))
-	case *dto.DataPointListItem_AggregateDatapoints:
-		fmt.Println("Data Points Count:", len(v.AggregateDatapoints.Datapoints))
-	default:
-		fmt.Println("Unknown data point type:", v)
-	}
+	fmt.Printf("Data Points Count: %d (%s)\n", dps.Len(), dps.Kind())
 	fmt.Printf("Time taken: %s\n", elapsed)
 
 	// Fetch data models
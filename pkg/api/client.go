@@ -97,6 +97,21 @@ type Units struct {
 }
 
 type DataModeling struct {
+	Client     *CogniteClient
+	Spaces     Spaces
+	Containers Containers
+	Views      Views
+}
+
+type Spaces struct {
+	Client *CogniteClient
+}
+
+type Containers struct {
+	Client *CogniteClient
+}
+
+type Views struct {
 	Client *CogniteClient
 }
 
@@ -119,6 +134,11 @@ func NewCogniteClient(clientConfig ClientConfig) CogniteClient {
 	}
 	client.TimeSeries = TimeSeries{Client: &client}
 	client.Units = Units{Client: &client}
-	client.DataModeling = DataModeling{Client: &client}
+	client.DataModeling = DataModeling{
+		Client:     &client,
+		Spaces:     Spaces{Client: &client},
+		Containers: Containers{Client: &client},
+		Views:      Views{Client: &client},
+	}
 	return client
 }
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -135,3 +137,22 @@ func TestToken_FetchToken(t *testing.T) {
 		t.Errorf("Expected test-access-token, got %s", result)
 	}
 }
+
+// newTestClient creates a client whose requests are served by handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) CogniteClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewCogniteClient(ClientConfig{
+		ClientName:  "test-client",
+		Cluster:     "test-cluster",
+		Project:     "test-project",
+		Credentials: &mockCredentialProvider{token: "test-token"},
+	})
+	// The services point at the client created inside NewCogniteClient
+	client.TimeSeries.Client.BaseURL = server.URL
+	client.BaseURL = server.URL
+	return client
+}
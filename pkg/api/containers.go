@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// containersItemsLimit is the maximum number of containers per apply, byids
+// or delete request.
+const containersItemsLimit = 100
+
+// List lists one page of containers, optionally in a single space. Pass the
+// NextCursor of the previous page as cursor to get the next one.
+func (c *Containers) List(limit int, cursor *string, space *string, includeGlobal bool) (dto.ContainerList, error) {
+	queryParams := map[string]interface{}{
+		"limit":         limit,
+		"includeGlobal": includeGlobal,
+	}
+	if cursor != nil {
+		queryParams["cursor"] = url.QueryEscape(*cursor)
+	}
+	if space != nil {
+		queryParams["space"] = url.QueryEscape(*space)
+	}
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/containers?%s", c.Client.ClientConfig.Project, buildQueryParams(queryParams))
+
+	var containerList dto.ContainerList
+	if err := doJSONRequest(c.Client, "GET", endpoint, nil, &containerList); err != nil {
+		return dto.ContainerList{}, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	return containerList, nil
+}
+
+// ListAll lists every container, optionally in a single space, following
+// the cursors.
+func (c *Containers) ListAll(space *string, includeGlobal bool) (dto.ContainerList, error) {
+	var all dto.ContainerList
+	var cursor *string
+	for {
+		page, err := c.List(1000, cursor, space, includeGlobal)
+		if err != nil {
+			return all, err
+		}
+		all.Items = append(all.Items, page.Items...)
+		if page.NextCursor == nil || *page.NextCursor == "" {
+			return all, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// Retrieve fetches containers by space and external ID. Unknown containers
+// are left out of the result.
+func (c *Containers) Retrieve(ids []dto.ContainerId) (dto.ContainerList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/containers/byids", c.Client.ClientConfig.Project)
+
+	var retrieved dto.ContainerList
+	for start := 0; start < len(ids); start += containersItemsLimit {
+		end := min(start+containersItemsLimit, len(ids))
+
+		var containerList dto.ContainerList
+		body := map[string]interface{}{"items": ids[start:end]}
+		if err := doJSONRequest(c.Client, "POST", endpoint, body, &containerList); err != nil {
+			return retrieved, fmt.Errorf("failed to retrieve containers: %w", err)
+		}
+		retrieved.Items = append(retrieved.Items, containerList.Items...)
+	}
+
+	return retrieved, nil
+}
+
+// Apply creates the containers that do not exist and updates the others.
+// CDF rejects updates that change the type of an existing property.
+func (c *Containers) Apply(items []dto.ContainerApply) (dto.ContainerList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/containers", c.Client.ClientConfig.Project)
+
+	var applied dto.ContainerList
+	for start := 0; start < len(items); start += containersItemsLimit {
+		end := min(start+containersItemsLimit, len(items))
+
+		var containerList dto.ContainerList
+		body := map[string]interface{}{"items": items[start:end]}
+		if err := doJSONRequest(c.Client, "POST", endpoint, body, &containerList); err != nil {
+			return applied, fmt.Errorf("failed to apply containers: %w", err)
+		}
+		applied.Items = append(applied.Items, containerList.Items...)
+	}
+
+	return applied, nil
+}
+
+// Delete deletes containers, together with the data stored in them, and
+// returns the containers CDF deleted.
+func (c *Containers) Delete(ids []dto.ContainerId) ([]dto.ContainerId, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/containers/delete", c.Client.ClientConfig.Project)
+
+	var deleted []dto.ContainerId
+	for start := 0; start < len(ids); start += containersItemsLimit {
+		end := min(start+containersItemsLimit, len(ids))
+
+		var response struct {
+			Items []dto.ContainerId `json:"items"`
+		}
+		body := map[string]interface{}{"items": ids[start:end]}
+		if err := doJSONRequest(c.Client, "POST", endpoint, body, &response); err != nil {
+			return deleted, fmt.Errorf("failed to delete containers: %w", err)
+		}
+		deleted = append(deleted, response.Items...)
+	}
+
+	return deleted, nil
+}
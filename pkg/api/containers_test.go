@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestContainers_Apply(t *testing.T) {
+	notNull := false
+	container := dto.ContainerApply{
+		Space:      "site-oslo",
+		ExternalId: "Pump",
+		UsedFor:    dto.ContainerUsedForNode,
+		Properties: map[string]dto.ContainerPropertyDefinition{
+			"name":     {Type: dto.PropertyType{Type: dto.PropertyTypeText, Collation: "ucs_basic"}, Nullable: &notNull},
+			"running":  {Type: dto.PropertyType{Type: dto.PropertyTypeBoolean}, DefaultValue: false},
+			"flow":     {Type: dto.PropertyType{Type: dto.PropertyTypeFloat64, Unit: &dto.PropertyUnit{ExternalId: "volume_flow_rate:m3-per-hr"}}},
+			"tags":     {Type: dto.PropertyType{Type: dto.PropertyTypeText, List: true, MaxListSize: 10}},
+			"site":     {Type: dto.PropertyType{Type: dto.PropertyTypeDirect, Container: &dto.ContainerReference{Type: "container", Space: "site-oslo", ExternalId: "Site"}}},
+			"pressure": {Type: dto.PropertyType{Type: dto.PropertyTypeTimeSeries}, Immutable: true},
+			"state": {Type: dto.PropertyType{Type: dto.PropertyTypeEnum, Values: map[string]dto.EnumValue{
+				"on":  {Name: "On"},
+				"off": {Name: "Off"},
+			}, UnknownValue: "off"}},
+		},
+		Constraints: map[string]dto.ContainerConstraint{
+			"uniqueName": {ConstraintType: dto.ConstraintTypeUniqueness, Properties: []string{"name"}},
+			"isAsset":    {ConstraintType: dto.ConstraintTypeRequires, Require: &dto.ContainerReference{Type: "container", Space: "cdf_cdm", ExternalId: "CogniteAsset"}},
+		},
+		Indexes: map[string]dto.ContainerIndex{
+			"byName": {IndexType: dto.IndexTypeBTree, Properties: []string{"name"}, Cursorable: true},
+			"byTags": {IndexType: dto.IndexTypeInverted, Properties: []string{"tags"}},
+		},
+	}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/projects/test-project/models/containers" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"items":[{"space":"site-oslo","externalId":"Pump","usedFor":"node","properties":{` +
+			`"flow":{"type":{"type":"float64","unit":{"externalId":"volume_flow_rate:m3-per-hr"}}},` +
+			`"name":{"type":{"type":"text","collation":"ucs_basic"},"nullable":false},` +
+			`"pressure":{"type":{"type":"timeseries"},"immutable":true},` +
+			`"running":{"type":{"type":"boolean"},"defaultValue":false},` +
+			`"site":{"type":{"type":"direct","container":{"type":"container","space":"site-oslo","externalId":"Site"}}},` +
+			`"state":{"type":{"type":"enum","values":{"off":{"name":"Off"},"on":{"name":"On"}},"unknownValue":"off"}},` +
+			`"tags":{"type":{"type":"text","list":true,"maxListSize":10}}},` +
+			`"constraints":{"isAsset":{"constraintType":"requires","require":{"type":"container","space":"cdf_cdm","externalId":"CogniteAsset"}},` +
+			`"uniqueName":{"constraintType":"uniqueness","properties":["name"]}},` +
+			`"indexes":{"byName":{"indexType":"btree","properties":["name"],"cursorable":true},` +
+			`"byTags":{"indexType":"inverted","properties":["tags"]}}}]}`
+		if string(body) != expected {
+			t.Errorf("Expected body:\n%s\ngot:\n%s", expected, body)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"space":"site-oslo","externalId":"Pump","usedFor":"node","isGlobal":false,` +
+			`"createdTime":1,"lastUpdatedTime":2,"properties":{"name":{"type":{"type":"text","list":false,"collation":"ucs_basic"},` +
+			`"nullable":false,"immutable":false,"autoIncrement":false}}}]}`))
+	})
+
+	applied, err := client.DataModeling.Containers.Apply([]dto.ContainerApply{container})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(applied.Items) != 1 {
+		t.Fatalf("Expected 1 container, got %d", len(applied.Items))
+	}
+	name := applied.Items[0].Properties["name"]
+	if name.Type.Type != dto.PropertyTypeText || name.Nullable == nil || *name.Nullable {
+		t.Errorf("Unexpected name property: %+v", name)
+	}
+}
+
+func TestContainers_ListRetrieveDelete(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/projects/test-project/models/containers":
+			if r.URL.Query().Get("space") != "site-oslo" || r.URL.Query().Get("includeGlobal") != "false" {
+				t.Errorf("Unexpected query %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(dto.ContainerList{Items: []dto.Container{{Space: "site-oslo", ExternalId: "Pump"}}})
+		case "/api/v1/projects/test-project/models/containers/byids", "/api/v1/projects/test-project/models/containers/delete":
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"items":[{"space":"site-oslo","externalId":"Pump"}]}` {
+				t.Errorf("Unexpected body %s", body)
+			}
+			_, _ = w.Write([]byte(`{"items":[{"space":"site-oslo","externalId":"Pump"}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	containers := client.DataModeling.Containers
+	ids := []dto.ContainerId{{Space: "site-oslo", ExternalId: "Pump"}}
+
+	space := "site-oslo"
+	list, err := containers.ListAll(&space, false)
+	if err != nil || len(list.Items) != 1 {
+		t.Errorf("Expected 1 container, got %+v, %v", list.Items, err)
+	}
+
+	retrieved, err := containers.Retrieve(ids)
+	if err != nil || len(retrieved.Items) != 1 || retrieved.Items[0].ExternalId != "Pump" {
+		t.Errorf("Expected the Pump container, got %+v, %v", retrieved.Items, err)
+	}
+
+	deleted, err := containers.Delete(ids)
+	if err != nil || len(deleted) != 1 || deleted[0] != ids[0] {
+		t.Errorf("Expected the Pump container to be deleted, got %v, %v", deleted, err)
+	}
+}
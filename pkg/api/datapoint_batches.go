@@ -0,0 +1,173 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+const (
+	// defaultRetrieveConcurrency is the number of concurrent requests
+	// RetrieveData uses when it has to split the items.
+	defaultRetrieveConcurrency = 4
+	// defaultDatapointsLimit is the per-item limit CDF applies when none is given.
+	defaultDatapointsLimit = 100
+)
+
+// dataPointsBatch is one request worth of query items together with their
+// positions in the original input.
+type dataPointsBatch struct {
+	indices []int
+	items   []dto.DataPointsQueryItem
+}
+
+// RetrieveDataBatched works like RetrieveData, but also returns the
+// identifiers of the items CDF did not know about when ignoreUnknownIds is
+// set. Items are packed into requests of at most 100 items whose limits add
+// up to at most 100000 raw or 10000 aggregate datapoints, and up to
+// concurrency requests are sent at the same time.
+func (t *TimeSeries) RetrieveDataBatched(
+	items *[]dto.DataPointsQueryItem,
+	startTime *string,
+	endTime *string,
+	limit *int64,
+	aggregates *[]string,
+	granularity *string,
+	includeOutsidePoints *bool,
+	timeZone *string,
+	ignoreUnknownIds *bool,
+	concurrency int,
+) (*dto.DataPointListResponse, []dto.Identity, error) {
+	if items == nil {
+		return nil, nil, errNilQueryItems
+	}
+	defaults := dataPointsDefaults{
+		Start:                startTime,
+		End:                  endTime,
+		Limit:                limit,
+		Aggregates:           aggregates,
+		Granularity:          granularity,
+		IncludeOutsidePoints: includeOutsidePoints,
+		TimeZone:             timeZone,
+		IgnoreUnknownIds:     ignoreUnknownIds,
+	}
+	return t.retrieveDataBatched(*items, defaults, concurrency)
+}
+
+// retrieveDataBatched implements RetrieveDataBatched for callers that need
+// request defaults RetrieveDataBatched does not expose.
+func (t *TimeSeries) retrieveDataBatched(
+	queries []dto.DataPointsQueryItem,
+	defaults dataPointsDefaults,
+	concurrency int,
+) (*dto.DataPointListResponse, []dto.Identity, error) {
+	if concurrency <= 0 {
+		concurrency = defaultRetrieveConcurrency
+	}
+	if err := validateDataPointsQuery(queries, defaults, nil, true); err != nil {
+		return nil, nil, err
+	}
+
+	batches := planDataPointsBatches(queries, defaults)
+	results := make([]*dto.DataPointListItem, len(queries))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	semaphore := make(chan struct{}, concurrency)
+	for _, batch := range batches {
+		wg.Add(1)
+		go func(batch dataPointsBatch) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			response, err := t.retrieveDataPage(&batch.items, defaults)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+
+			// Unknown items are missing from the response when ignoreUnknownIds is set
+			k := 0
+			for i, index := range batch.indices {
+				if k < len(response.Items) && matchesQueryItem(&batch.items[i], response.Items[k]) {
+					results[index] = response.Items[k]
+					k++
+				}
+			}
+			if k != len(response.Items) {
+				firstErr = fmt.Errorf("failed to match %d datapoint items to the query", len(response.Items)-k)
+			}
+		}(batch)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+
+	response := &dto.DataPointListResponse{}
+	var unknown []dto.Identity
+	for i, item := range results {
+		if item == nil {
+			unknown = append(unknown, dto.Identity{
+				Id:         queries[i].Id,
+				ExternalId: queries[i].ExternalId,
+				InstanceId: queries[i].InstanceId,
+			})
+			continue
+		}
+		response.Items = append(response.Items, item)
+	}
+	return response, unknown, nil
+}
+
+// planDataPointsBatches packs the query items, in order, into batches that
+// respect CDF's items and datapoints limits. Raw and aggregate queries are
+// never mixed in one batch.
+func planDataPointsBatches(items []dto.DataPointsQueryItem, defaults dataPointsDefaults) []dataPointsBatch {
+	defaultLimit := int64(defaultDatapointsLimit)
+	if defaults.Limit != nil {
+		defaultLimit = *defaults.Limit
+	}
+	defaultAggregates := defaults.Aggregates != nil && len(*defaults.Aggregates) > 0
+
+	var batches []dataPointsBatch
+	var raw, aggregate dataPointsBatch
+	var rawPoints, aggregatePoints int64
+
+	add := func(current *dataPointsBatch, points *int64, budget int64, index int, itemLimit int64) {
+		if len(current.items) == retrieveDataItemsLimit || (len(current.items) > 0 && *points+itemLimit > budget) {
+			batches = append(batches, *current)
+			*current = dataPointsBatch{}
+			*points = 0
+		}
+		current.indices = append(current.indices, index)
+		current.items = append(current.items, items[index])
+		*points += itemLimit
+	}
+
+	for i := range items {
+		itemLimit := items[i].Limit
+		if itemLimit == 0 {
+			itemLimit = defaultLimit
+		}
+		if len(items[i].Aggregates) > 0 || defaultAggregates {
+			add(&aggregate, &aggregatePoints, retrieveAggregateDatapointsLimit, i, itemLimit)
+		} else {
+			add(&raw, &rawPoints, retrieveRawDatapointsLimit, i, itemLimit)
+		}
+	}
+
+	for _, batch := range []dataPointsBatch{raw, aggregate} {
+		if len(batch.items) > 0 {
+			batches = append(batches, batch)
+		}
+	}
+	return batches
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestPlanDataPointsBatches(t *testing.T) {
+	var items []dto.DataPointsQueryItem
+	// 250 raw items with the default limit of 100 fit in 100-item batches
+	for i := 0; i < 250; i++ {
+		items = append(items, dto.DataPointsQueryItem{Id: int64(i + 1)})
+	}
+	// Three aggregate items of 4000 need two batches
+	for i := 0; i < 3; i++ {
+		items = append(items, dto.DataPointsQueryItem{Id: int64(1000 + i), Aggregates: []string{dto.AggregateAverage}, Limit: 4000})
+	}
+	// Two raw items of 60000 cannot share a batch; the first joins the last 50
+	items = append(items,
+		dto.DataPointsQueryItem{Id: 2000, Limit: 60000},
+		dto.DataPointsQueryItem{Id: 2001, Limit: 60000},
+	)
+
+	batches := planDataPointsBatches(items, dataPointsDefaults{})
+
+	var sizes []int
+	seen := make(map[int]bool)
+	for _, batch := range batches {
+		sizes = append(sizes, len(batch.items))
+		for i, index := range batch.indices {
+			if batch.items[i].Id != items[index].Id {
+				t.Errorf("Batch item %d does not match input item %d", i, index)
+			}
+			seen[index] = true
+		}
+	}
+	if len(seen) != len(items) {
+		t.Errorf("Expected every item to be batched once, got %d of %d", len(seen), len(items))
+	}
+
+	expected := []int{100, 100, 2, 51, 1, 1}
+	if fmt.Sprint(sizes) != fmt.Sprint(expected) {
+		t.Errorf("Expected batch sizes %v, got %v", expected, sizes)
+	}
+}
+
+func TestTimeSeries_RetrieveDataBatched(t *testing.T) {
+	fake := &fakeDatapointsServer{series: map[string][]int64{}}
+	var items []dto.DataPointsQueryItem
+	for i := 0; i < 150; i++ {
+		externalId := fmt.Sprintf("ts-%d", i)
+		if i%50 != 7 {
+			fake.series[externalId] = sequence(3)
+		}
+		items = append(items, dto.DataPointsQueryItem{ExternalId: externalId, Limit: 10})
+	}
+	client := newTestClient(t, fake.handler(t))
+
+	ignoreUnknownIds := true
+	response, unknown, err := client.TimeSeries.RetrieveDataBatched(&items, nil, nil, nil, nil, nil, nil, nil, &ignoreUnknownIds, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(fake.requests) != 2 {
+		t.Errorf("Expected 2 requests, got %d", len(fake.requests))
+	}
+	for _, request := range fake.requests {
+		if len(request.Items) > retrieveDataItemsLimit {
+			t.Errorf("Request has %d items, exceeding the limit", len(request.Items))
+		}
+	}
+
+	if len(response.Items) != 147 {
+		t.Fatalf("Expected 147 items, got %d", len(response.Items))
+	}
+	k := 0
+	for i := range items {
+		if _, ok := fake.series[items[i].ExternalId]; !ok {
+			continue
+		}
+		if response.Items[k].ExternalId != items[i].ExternalId {
+			t.Fatalf("Item %d: expected %s, got %s", k, items[i].ExternalId, response.Items[k].ExternalId)
+		}
+		k++
+	}
+
+	expectedUnknown := []string{"ts-7", "ts-57", "ts-107"}
+	if len(unknown) != len(expectedUnknown) {
+		t.Fatalf("Expected unknown %v, got %v", expectedUnknown, unknown)
+	}
+	for i, externalId := range expectedUnknown {
+		if unknown[i].ExternalId != externalId {
+			t.Errorf("Expected unknown %s, got %s", externalId, unknown[i].ExternalId)
+		}
+	}
+}
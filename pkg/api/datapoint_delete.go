@@ -0,0 +1,195 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+const (
+	// deleteDataItemsLimit is the maximum number of ranges per delete request.
+	deleteDataItemsLimit = 10000
+	// maxHourGranularity is the largest hour granularity CDF accepts.
+	maxHourGranularity = 100000
+)
+
+// DeleteDataResult reports the outcome of DeleteData. Counts holds the
+// number of datapoints per input item and is only filled in for dry runs.
+type DeleteDataResult struct {
+	DryRun bool
+	Counts []int64
+	Total  int64
+}
+
+// dataPointsDeleteRange is the wire format of dto.DataPointsDeleteItem.
+type dataPointsDeleteRange struct {
+	Id             int64           `json:"id,omitempty"`
+	ExternalId     string          `json:"externalId,omitempty"`
+	InstanceId     *dto.InstanceId `json:"instanceId,omitempty"`
+	InclusiveBegin int64           `json:"inclusiveBegin"`
+	ExclusiveEnd   int64           `json:"exclusiveEnd"`
+}
+
+// DeleteData deletes the datapoints in each item's [InclusiveBegin,
+// ExclusiveEnd) range. When ExclusiveEnd is nil only the datapoint at
+// InclusiveBegin is deleted.
+//
+// With dryRun set nothing is deleted; instead the datapoints in each range
+// are counted, exactly the ones DeleteData would delete, including those
+// with a bad status code.
+func (t *TimeSeries) DeleteData(items []dto.DataPointsDeleteItem, dryRun bool) (DeleteDataResult, error) {
+	ranges, err := resolveDeleteRanges(items, time.Now())
+	if err != nil {
+		return DeleteDataResult{}, err
+	}
+
+	if dryRun {
+		return t.countDeleteRanges(ranges)
+	}
+
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/data/delete", t.Client.ClientConfig.Project)
+	for start := 0; start < len(ranges); start += deleteDataItemsLimit {
+		end := min(start+deleteDataItemsLimit, len(ranges))
+
+		body := map[string]interface{}{"items": ranges[start:end]}
+		if err := doJSONRequest(t.Client, "POST", endpoint, body, nil); err != nil {
+			return DeleteDataResult{}, fmt.Errorf("failed to delete datapoints: %w", err)
+		}
+	}
+
+	return DeleteDataResult{}, nil
+}
+
+func resolveDeleteRanges(items []dto.DataPointsDeleteItem, now time.Time) ([]dataPointsDeleteRange, error) {
+	ranges := make([]dataPointsDeleteRange, 0, len(items))
+	for i, item := range items {
+		if item.Id == 0 && item.ExternalId == "" && item.InstanceId == nil {
+			return nil, fmt.Errorf("delete item %d has no id, externalId or instanceId", i)
+		}
+		begin, err := resolveTimestamp(item.InclusiveBegin, now)
+		if err != nil {
+			return nil, fmt.Errorf("delete item %d: inclusiveBegin: %w", i, err)
+		}
+		end := begin + 1
+		if item.ExclusiveEnd != nil {
+			end, err = resolveTimestamp(item.ExclusiveEnd, now)
+			if err != nil {
+				return nil, fmt.Errorf("delete item %d: exclusiveEnd: %w", i, err)
+			}
+		}
+		if end <= begin {
+			return nil, fmt.Errorf("delete item %d: exclusiveEnd must be after inclusiveBegin", i)
+		}
+		ranges = append(ranges, dataPointsDeleteRange{
+			Id:             item.Id,
+			ExternalId:     item.ExternalId,
+			InstanceId:     item.InstanceId,
+			InclusiveBegin: begin,
+			ExclusiveEnd:   end,
+		})
+	}
+	return ranges, nil
+}
+
+// countDeleteRanges counts the datapoints in each range exactly. Aggregate
+// buckets cover whole hours, so the whole hours inside a range are counted
+// with the count aggregate, one bucket per query, and the partial hours at
+// its edges by paging through the raw datapoints. CDF leaves datapoints with
+// a bad status out of both unless ignoreBadDataPoints is false, while the
+// delete removes them.
+func (t *TimeSeries) countDeleteRanges(ranges []dataPointsDeleteRange) (DeleteDataResult, error) {
+	result := DeleteDataResult{DryRun: true, Counts: make([]int64, len(ranges))}
+	ignoreBadDataPoints := false
+	defaults := dataPointsDefaults{IgnoreBadDataPoints: &ignoreBadDataPoints}
+
+	// Queries and the index of the range each of them counts for
+	var aggregateQueries, rawQueries []dto.DataPointsQueryItem
+	var aggregateRanges, rawRanges []int
+	addQuery := func(index int, begin, end int64, hours int64) {
+		r := ranges[index]
+		query := dto.DataPointsQueryItem{
+			Id:         r.Id,
+			ExternalId: r.ExternalId,
+			InstanceId: r.InstanceId,
+			Start:      strconv.FormatInt(begin, 10),
+			End:        strconv.FormatInt(end, 10),
+		}
+		if hours == 0 {
+			rawQueries = append(rawQueries, query)
+			rawRanges = append(rawRanges, index)
+			return
+		}
+		query.Aggregates = []string{dto.AggregateCount}
+		query.Granularity = fmt.Sprintf("%dh", hours)
+		aggregateQueries = append(aggregateQueries, query)
+		aggregateRanges = append(aggregateRanges, index)
+	}
+
+	hour := time.Hour.Milliseconds()
+	for i, r := range ranges {
+		wholeBegin := floorDiv(r.InclusiveBegin+hour-1, hour) * hour
+		wholeEnd := floorDiv(r.ExclusiveEnd, hour) * hour
+		if wholeBegin >= wholeEnd {
+			addQuery(i, r.InclusiveBegin, r.ExclusiveEnd, 0)
+			continue
+		}
+		if r.InclusiveBegin < wholeBegin {
+			addQuery(i, r.InclusiveBegin, wholeBegin, 0)
+		}
+		for begin := wholeBegin; begin < wholeEnd; begin += maxHourGranularity * hour {
+			end := min(begin+maxHourGranularity*hour, wholeEnd)
+			addQuery(i, begin, end, (end-begin)/hour)
+		}
+		if wholeEnd < r.ExclusiveEnd {
+			addQuery(i, wholeEnd, r.ExclusiveEnd, 0)
+		}
+	}
+
+	for start := 0; start < len(aggregateQueries); start += retrieveDataItemsLimit {
+		end := min(start+retrieveDataItemsLimit, len(aggregateQueries))
+		queryItems := aggregateQueries[start:end]
+
+		response, _, err := t.retrieveDataBatched(queryItems, defaults, defaultRetrieveConcurrency)
+		if err != nil {
+			return DeleteDataResult{}, fmt.Errorf("failed to count datapoints: %w", err)
+		}
+		if len(response.Items) != len(queryItems) {
+			return DeleteDataResult{}, fmt.Errorf("failed to count datapoints: expected %d items, got %d", len(queryItems), len(response.Items))
+		}
+		for i, item := range response.Items {
+			counts, err := item.AggregateValues(dto.AggregateCount)
+			if err != nil {
+				return DeleteDataResult{}, err
+			}
+			for _, c := range counts {
+				result.Counts[aggregateRanges[start+i]] += int64(c)
+			}
+		}
+	}
+
+	if len(rawQueries) > 0 {
+		err := t.retrieveAllPages(rawQueries, defaults, func(index int, page *dto.DataPointListItem) error {
+			result.Counts[rawRanges[index]] += int64(page.Len())
+			return nil
+		})
+		if err != nil {
+			return DeleteDataResult{}, fmt.Errorf("failed to count datapoints: %w", err)
+		}
+	}
+
+	for _, count := range result.Counts {
+		result.Total += count
+	}
+	return result, nil
+}
+
+// floorDiv divides a by b, rounding towards negative infinity.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// writeProto writes msg as a protobuf response
+func writeProto(t *testing.T, w http.ResponseWriter, msg proto.Message) {
+	t.Helper()
+
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to encode protobuf response: %v", err)
+	}
+	w.Header().Set("Content-Type", "application/protobuf")
+	_, _ = w.Write(body)
+}
+
+func TestResolveDeleteRanges(t *testing.T) {
+	now := time.UnixMilli(1_000_000_000)
+
+	ranges, err := resolveDeleteRanges([]dto.DataPointsDeleteItem{
+		{ExternalId: "a", InclusiveBegin: "1h-ago", ExclusiveEnd: "now"},
+		{Id: 1, InclusiveBegin: now.Add(-time.Minute), ExclusiveEnd: int64(1_000_000_000)},
+		{Id: 2, InclusiveBegin: int64(500)},
+	}, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if ranges[0].InclusiveBegin != 1_000_000_000-3_600_000 || ranges[0].ExclusiveEnd != 1_000_000_000 {
+		t.Errorf("Unexpected relative range %+v", ranges[0])
+	}
+	if ranges[1].InclusiveBegin != 1_000_000_000-60_000 {
+		t.Errorf("Unexpected time.Time range %+v", ranges[1])
+	}
+	if ranges[2].ExclusiveEnd != 501 {
+		t.Errorf("Expected single point range, got %+v", ranges[2])
+	}
+
+	invalid := [][]dto.DataPointsDeleteItem{
+		{{InclusiveBegin: "now"}},
+		{{Id: 1, InclusiveBegin: "now", ExclusiveEnd: "1d-ago"}},
+		{{Id: 1, InclusiveBegin: "yesterday"}},
+	}
+	for _, items := range invalid {
+		if _, err := resolveDeleteRanges(items, now); err == nil {
+			t.Errorf("Expected error for %+v", items)
+		}
+	}
+}
+
+func TestTimeSeries_DeleteData(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/test-project/timeseries/data/delete" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"items":[{"externalId":"a","inclusiveBegin":1000,"exclusiveEnd":2000}]}`
+		if string(body) != expected {
+			t.Errorf("Unexpected body\n got: %s\nwant: %s", body, expected)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	_, err := client.TimeSeries.DeleteData([]dto.DataPointsDeleteItem{
+		{ExternalId: "a", InclusiveBegin: int64(1000), ExclusiveEnd: time.UnixMilli(2000)},
+	}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestTimeSeries_DeleteData_dryRun(t *testing.T) {
+	// A datapoint every 7 minutes for three days
+	var timestamps []int64
+	for ts := int64(0); ts < (72 * time.Hour).Milliseconds(); ts += (7 * time.Minute).Milliseconds() {
+		timestamps = append(timestamps, ts)
+	}
+	// Every fifth datapoint has a bad status
+	bad := make(map[int64]bool)
+	for i := 0; i < len(timestamps); i += 5 {
+		bad[timestamps[i]] = true
+	}
+	fake := &fakeDatapointsServer{series: map[string][]int64{"a": timestamps, "b": {}}, bad: bad}
+	var deleted int64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/timeseries/data/delete") {
+			// The delete removes datapoints whatever their status
+			var body struct {
+				Items []dataPointsDeleteRange `json:"items"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			for _, item := range body.Items {
+				for _, ts := range fake.series[item.ExternalId] {
+					if ts >= item.InclusiveBegin && ts < item.ExclusiveEnd {
+						deleted++
+					}
+				}
+			}
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/timeseries/data/list") {
+			t.Errorf("Expected only count requests in a dry run, got %s", r.URL.Path)
+			return
+		}
+		fake.handler(t)(w, r)
+	})
+
+	hour := time.Hour.Milliseconds()
+	items := []dto.DataPointsDeleteItem{
+		// Partial hours on both sides of 39 whole hours
+		{ExternalId: "a", InclusiveBegin: hour + 1, ExclusiveEnd: 41*hour + 30*60000},
+		// Within a single hour
+		{ExternalId: "a", InclusiveBegin: 2*hour + 60000, ExclusiveEnd: 2*hour + 50*60000},
+		// Exactly whole hours
+		{ExternalId: "a", InclusiveBegin: int64(0), ExclusiveEnd: 24 * hour},
+		{ExternalId: "b", InclusiveBegin: int64(0), ExclusiveEnd: 24 * hour},
+	}
+	result, err := client.TimeSeries.DeleteData(items, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var total int64
+	for i, item := range items {
+		var expected int64
+		for _, ts := range fake.series[item.ExternalId] {
+			if ts >= item.InclusiveBegin.(int64) && ts < item.ExclusiveEnd.(int64) {
+				expected++
+			}
+		}
+		if result.Counts[i] != expected {
+			t.Errorf("Item %d: expected %d datapoints, got %d", i, expected, result.Counts[i])
+		}
+		total += expected
+	}
+	if !result.DryRun || result.Total != total {
+		t.Errorf("Expected a dry run counting %d datapoints, got %+v", total, result)
+	}
+	if len(fake.requests) == 0 {
+		t.Fatal("Expected count requests")
+	}
+	for _, request := range fake.requests {
+		if request.IgnoreBadDataPoints == nil || *request.IgnoreBadDataPoints {
+			t.Errorf("Expected the counts to include bad datapoints, got ignoreBadDataPoints %v", request.IgnoreBadDataPoints)
+		}
+	}
+
+	if _, err := client.TimeSeries.DeleteData(items, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The fake keeps the datapoints it deletes, so overlapping ranges count
+	// per range, as in the dry run
+	if deleted != result.Total {
+		t.Errorf("Expected the dry run to count the %d datapoints deleted, got %d", deleted, result.Total)
+	}
+
+	// The whole hours are counted with one aggregate bucket per query
+	for _, request := range fake.requests {
+		for _, query := range request.Items {
+			if len(query.Aggregates) > 0 && query.Granularity != "39h" && query.Granularity != "24h" {
+				t.Errorf("Unexpected granularity %s", query.Granularity)
+			}
+		}
+	}
+}
+
+func TestFloorDiv(t *testing.T) {
+	tests := []struct {
+		a, b, expected int64
+	}{
+		{7, 2, 3},
+		{-7, 2, -4},
+		{-8, 2, -4},
+		{0, 3, 0},
+	}
+	for _, tt := range tests {
+		if got := floorDiv(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Expected floorDiv(%d, %d) = %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// FetchPlannerConfig controls how RetrieveAllConcurrently splits and fetches
+// large ranges. Zero values are replaced with defaults.
+type FetchPlannerConfig struct {
+	// Concurrency is the maximum number of requests in flight. It defaults to 8.
+	Concurrency int
+	// DensityGranularity is the granularity of the count aggregate used to
+	// estimate how datapoints are spread over the range. It defaults to "1d".
+	DensityGranularity dto.Granularity
+	// MaxDatapointsPerSlice is the estimated number of datapoints each time
+	// slice should hold. It defaults to 100000, the per-request limit.
+	MaxDatapointsPerSlice int64
+	// OnFallback, when set, is called for every item that is fetched without
+	// slicing because CDF rejected its count aggregate, with the index of the
+	// item and the error.
+	OnFallback func(index int, err error)
+}
+
+// timeSlice is a half-open [start, end) range in epoch milliseconds.
+type timeSlice struct {
+	start int64
+	end   int64
+}
+
+// RetrieveAllConcurrently fetches complete raw ranges like RetrieveAll, but
+// first estimates each series' density with the count aggregate, splits its
+// range into time slices expected to fit in one request, and fetches the
+// slices concurrently. The slices are merged in timestamp order, one
+// DataPointListItem per input item.
+//
+// Items must carry their own Start and End (defaulting to 0 and "now"), and
+// every range is validated before any request is sent. Items with aggregates
+// or a Limit are fetched with RetrieveAll without slicing. So are items whose
+// count aggregate CDF rejects with a 400, such as string series; those are
+// reported to config.OnFallback. Other errors while estimating densities are
+// returned.
+func (t *TimeSeries) RetrieveAllConcurrently(
+	items *[]dto.DataPointsQueryItem,
+	config FetchPlannerConfig,
+) (*dto.DataPointListResponse, error) {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 8
+	}
+	if config.DensityGranularity == "" {
+		config.DensityGranularity = "1d"
+	}
+	if err := config.DensityGranularity.Validate(); err != nil {
+		return nil, err
+	}
+	if config.MaxDatapointsPerSlice <= 0 {
+		config.MaxDatapointsPerSlice = retrieveRawDatapointsLimit
+	}
+
+	queries := *items
+	now := time.Now()
+	semaphore := make(chan struct{}, config.Concurrency)
+
+	// Resolve every range up front, so a bad item fails before any request
+	plans := make([][]timeSlice, len(queries))
+	ranges := make([]timeSlice, len(queries))
+	for i := range queries {
+		if len(queries[i].Aggregates) > 0 || queries[i].Limit > 0 {
+			continue
+		}
+		start, end, err := resolveQueryRange(&queries[i], now)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		ranges[i] = timeSlice{start: start, end: end}
+		plans[i] = []timeSlice{ranges[i]}
+	}
+
+	// Plan the slices of every item, estimating densities concurrently
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var planErr error
+	for i := range queries {
+		r := ranges[i]
+		if plans[i] == nil || r.end <= r.start {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			buckets, err := t.countDensity(&queries[i], r.start, r.end, config.DensityGranularity)
+			mu.Lock()
+			defer mu.Unlock()
+			var apiErr *APIError
+			switch {
+			case err == nil:
+				plans[i] = planSlices(r.start, r.end, buckets, config.MaxDatapointsPerSlice)
+			case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest:
+				// Keep the single slice; errors such as unknown ids
+				// surface when it is fetched
+				if config.OnFallback != nil {
+					config.OnFallback(i, err)
+				}
+			case planErr == nil:
+				planErr = fmt.Errorf("item %d: failed to estimate density: %w", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if planErr != nil {
+		return nil, planErr
+	}
+
+	// Fetch every slice (or whole item when it is not planned)
+	type sliceResult struct {
+		slice timeSlice
+		item  *dto.DataPointListItem
+	}
+	results := make([][]sliceResult, len(queries))
+	var fetchErr error
+	for i := range queries {
+		slices := plans[i]
+		if slices == nil {
+			slices = []timeSlice{{}}
+		}
+		results[i] = make([]sliceResult, len(slices))
+		for j, slice := range slices {
+			sliceQuery := queries[i]
+			if plans[i] != nil {
+				sliceQuery.Start = strconv.FormatInt(slice.start, 10)
+				sliceQuery.End = strconv.FormatInt(slice.end, 10)
+			}
+
+			wg.Add(1)
+			go func(i, j int, slice timeSlice, sliceQuery dto.DataPointsQueryItem) {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+
+				sliceItems := []dto.DataPointsQueryItem{sliceQuery}
+				response, err := t.RetrieveAll(&sliceItems, nil, nil, nil, nil, nil, nil, nil, nil)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if fetchErr == nil {
+						fetchErr = fmt.Errorf("item %d: %w", i, err)
+					}
+					return
+				}
+				if len(response.Items) > 0 {
+					results[i][j] = sliceResult{slice: slice, item: response.Items[0]}
+				}
+			}(i, j, slice, sliceQuery)
+		}
+	}
+	wg.Wait()
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	// Merge the slices of each item in timestamp order
+	response := &dto.DataPointListResponse{}
+	for i := range results {
+		sort.Slice(results[i], func(a, b int) bool { return results[i][a].slice.start < results[i][b].slice.start })
+		var merged *dto.DataPointListItem
+		for _, result := range results[i] {
+			if result.item == nil {
+				continue
+			}
+			if merged == nil {
+				merged = result.item
+				continue
+			}
+			appendDatapoints(merged, result.item)
+		}
+		if merged != nil {
+			response.Items = append(response.Items, merged)
+		}
+	}
+	return response, nil
+}
+
+// resolveQueryRange resolves the Start and End of a query item to epoch
+// milliseconds, using CDF's defaults of 0 and now.
+func resolveQueryRange(query *dto.DataPointsQueryItem, now time.Time) (int64, int64, error) {
+	start, end := int64(0), now.UnixMilli()
+	var err error
+	if query.Start != "" {
+		if start, err = resolveTimestamp(query.Start, now); err != nil {
+			return 0, 0, fmt.Errorf("start: %w", err)
+		}
+	}
+	if query.End != "" {
+		if end, err = resolveTimestamp(query.End, now); err != nil {
+			return 0, 0, fmt.Errorf("end: %w", err)
+		}
+	}
+	return start, end, nil
+}
+
+// countDensity returns the count aggregate of the series in [start, end).
+func (t *TimeSeries) countDensity(
+	query *dto.DataPointsQueryItem,
+	start int64,
+	end int64,
+	granularity dto.Granularity,
+) ([]*dto.AggregateDatapoint, error) {
+	countItems := []dto.DataPointsQueryItem{{
+		Id:          query.Id,
+		ExternalId:  query.ExternalId,
+		InstanceId:  query.InstanceId,
+		Start:       strconv.FormatInt(start, 10),
+		End:         strconv.FormatInt(end, 10),
+		Aggregates:  []string{dto.AggregateCount},
+		Granularity: string(granularity),
+	}}
+	response, err := t.RetrieveAll(&countItems, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		return nil, fmt.Errorf("no count aggregate returned")
+	}
+	return response.Items[0].Aggregates(), nil
+}
+
+// planSlices splits [start, end) at bucket boundaries so that each slice is
+// estimated to hold at most maxPerSlice datapoints. A single bucket denser
+// than maxPerSlice becomes a slice of its own.
+func planSlices(start, end int64, buckets []*dto.AggregateDatapoint, maxPerSlice int64) []timeSlice {
+	var slices []timeSlice
+	sliceStart := start
+	var sliceCount int64
+	for _, bucket := range buckets {
+		count := int64(bucket.GetCount())
+		boundary := bucket.GetTimestamp()
+		if sliceCount > 0 && sliceCount+count > maxPerSlice && boundary > sliceStart && boundary < end {
+			slices = append(slices, timeSlice{start: sliceStart, end: boundary})
+			sliceStart = boundary
+			sliceCount = 0
+		}
+		sliceCount += count
+	}
+	return append(slices, timeSlice{start: sliceStart, end: end})
+}
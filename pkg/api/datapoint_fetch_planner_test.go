@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestPlanSlices(t *testing.T) {
+	buckets := []*dto.AggregateDatapoint{
+		{Timestamp: 0, Count: 40},
+		{Timestamp: 100, Count: 40},
+		{Timestamp: 200, Count: 40},
+		{Timestamp: 300, Count: 250},
+		{Timestamp: 400, Count: 10},
+	}
+
+	slices := planSlices(50, 450, buckets, 100)
+
+	expected := []timeSlice{{50, 200}, {200, 300}, {300, 400}, {400, 450}}
+	if len(slices) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, slices)
+	}
+	for i := range expected {
+		if slices[i] != expected[i] {
+			t.Errorf("Slice %d: expected %v, got %v", i, expected[i], slices[i])
+		}
+	}
+
+	if slices := planSlices(0, 10, nil, 100); len(slices) != 1 || slices[0] != (timeSlice{0, 10}) {
+		t.Errorf("Expected a single slice without buckets, got %v", slices)
+	}
+}
+
+func TestTimeSeries_RetrieveAllConcurrently(t *testing.T) {
+	// Dense series with one datapoint per 10ms over 30 minutes
+	var dense []int64
+	for ts := int64(0); ts < 30*60000; ts += 10 {
+		dense = append(dense, ts)
+	}
+	fake := &fakeDatapointsServer{series: map[string][]int64{
+		"dense":  dense,
+		"sparse": sequence(5),
+	}}
+	client := newTestClient(t, fake.handler(t))
+
+	items := []dto.DataPointsQueryItem{
+		{ExternalId: "dense", Start: "0", End: "1800000"},
+		{ExternalId: "sparse", Start: "0", End: "1000"},
+	}
+	response, err := client.TimeSeries.RetrieveAllConcurrently(&items, FetchPlannerConfig{
+		Concurrency:           4,
+		DensityGranularity:    "1m",
+		MaxDatapointsPerSlice: 20000,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(response.Items) != 2 || response.Items[0].ExternalId != "dense" || response.Items[1].ExternalId != "sparse" {
+		t.Fatalf("Unexpected items %v", response.Items)
+	}
+	points := response.Items[0].GetNumericDatapoints().GetDatapoints()
+	if len(points) != len(dense) {
+		t.Fatalf("Expected %d datapoints, got %d", len(dense), len(points))
+	}
+	for i, point := range points {
+		if point.Timestamp != dense[i] {
+			t.Fatalf("Datapoint %d has timestamp %d, want %d", i, point.Timestamp, dense[i])
+		}
+	}
+
+	// 180000 datapoints in slices of ~20000 need several raw requests
+	rawRequests := 0
+	for _, request := range fake.requests {
+		if len(request.Items[0].Aggregates) == 0 && request.Items[0].ExternalId == "dense" {
+			rawRequests++
+		}
+	}
+	if rawRequests < 9 {
+		t.Errorf("Expected the dense range to be sliced, got %d raw requests", rawRequests)
+	}
+}
+
+func TestTimeSeries_RetrieveAllConcurrently_invalidRange(t *testing.T) {
+	fake := &fakeDatapointsServer{series: map[string][]int64{"a": sequence(10), "b": sequence(10)}}
+	client := newTestClient(t, fake.handler(t))
+
+	items := []dto.DataPointsQueryItem{
+		{ExternalId: "a", Start: "0", End: "1000"},
+		{ExternalId: "b", Start: "yesterday"},
+	}
+	_, err := client.TimeSeries.RetrieveAllConcurrently(&items, FetchPlannerConfig{})
+	if err == nil || !strings.Contains(err.Error(), "item 1") {
+		t.Errorf("Expected an error for item 1, got %v", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("Expected no requests before the ranges are valid, got %d", len(fake.requests))
+	}
+}
+
+func TestTimeSeries_RetrieveAllConcurrently_countErrors(t *testing.T) {
+	items := []dto.DataPointsQueryItem{{ExternalId: "a", Start: "0", End: "1000"}}
+
+	t.Run("Rejected count falls back to a single slice", func(t *testing.T) {
+		fake := &fakeDatapointsServer{series: map[string][]int64{"a": sequence(10)}, countStatus: http.StatusBadRequest}
+		client := newTestClient(t, fake.handler(t))
+
+		var fallbacks []int
+		response, err := client.TimeSeries.RetrieveAllConcurrently(&items, FetchPlannerConfig{
+			OnFallback: func(index int, err error) { fallbacks = append(fallbacks, index) },
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(response.Items) != 1 || response.Items[0].Len() != 10 {
+			t.Errorf("Expected the 10 datapoints, got %v", response.Items)
+		}
+		if len(fallbacks) != 1 || fallbacks[0] != 0 {
+			t.Errorf("Expected item 0 to be reported as a fallback, got %v", fallbacks)
+		}
+	})
+
+	t.Run("Other count errors are returned", func(t *testing.T) {
+		fake := &fakeDatapointsServer{series: map[string][]int64{"a": sequence(10)}, countStatus: http.StatusUnauthorized}
+		client := newTestClient(t, fake.handler(t))
+
+		_, err := client.TimeSeries.RetrieveAllConcurrently(&items, FetchPlannerConfig{})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected the count error to be returned, got %v", err)
+		}
+	})
+}
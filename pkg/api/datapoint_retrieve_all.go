@@ -0,0 +1,283 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+const (
+	// retrieveDataItemsLimit is the maximum number of items per datapoints
+	// retrieval request.
+	retrieveDataItemsLimit = 100
+	// retrieveRawDatapointsLimit is the maximum number of raw datapoints per
+	// retrieval request, summed over all items.
+	retrieveRawDatapointsLimit = 100000
+	// retrieveAggregateDatapointsLimit is the maximum number of aggregate
+	// datapoints per retrieval request, summed over all items.
+	retrieveAggregateDatapointsLimit = 10000
+)
+
+// errNilQueryItems is returned by the datapoint retrievals when items is nil.
+var errNilQueryItems = errors.New("datapoints query items must not be nil")
+
+// RetrieveAll works like RetrieveData but follows each item's cursor until
+// its whole range has been fetched or its limit is reached. An item's Limit
+// (or the top-level limit for items without one) is the total number of
+// datapoints to fetch; leave both unset to fetch the whole range.
+//
+// Items are paged independently and their pages are stitched into one
+// DataPointListItem per series, in input order. NextCursor is only set on
+// items that stopped because of their limit. Unknown items are left out when
+// ignoreUnknownIds is set.
+func (t *TimeSeries) RetrieveAll(
+	items *[]dto.DataPointsQueryItem,
+	startTime *string,
+	endTime *string,
+	limit *int64,
+	aggregates *[]string,
+	granularity *string,
+	includeOutsidePoints *bool,
+	timeZone *string,
+	ignoreUnknownIds *bool,
+) (*dto.DataPointListResponse, error) {
+	if items == nil {
+		return nil, errNilQueryItems
+	}
+	results := make([]*dto.DataPointListItem, len(*items))
+	err := t.RetrieveAllFunc(
+		items, startTime, endTime, limit, aggregates, granularity, includeOutsidePoints, timeZone, ignoreUnknownIds,
+		func(index int, page *dto.DataPointListItem) error {
+			if results[index] == nil {
+				results[index] = page
+				return nil
+			}
+			appendDatapoints(results[index], page)
+			results[index].NextCursor = page.NextCursor
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DataPointListResponse{}
+	for _, item := range results {
+		if item != nil {
+			response.Items = append(response.Items, item)
+		}
+	}
+	return response, nil
+}
+
+// RetrieveAllFunc fetches the same datapoints as RetrieveAll, but passes each
+// page to fn as it arrives instead of holding the datapoints in memory, so
+// large ranges can be streamed to disk. index is the position of the page's
+// item in items. Pages of one item arrive in timestamp order, but pages of
+// different items may be interleaved. An error returned by fn stops the
+// retrieval and is returned as is.
+func (t *TimeSeries) RetrieveAllFunc(
+	items *[]dto.DataPointsQueryItem,
+	startTime *string,
+	endTime *string,
+	limit *int64,
+	aggregates *[]string,
+	granularity *string,
+	includeOutsidePoints *bool,
+	timeZone *string,
+	ignoreUnknownIds *bool,
+	fn func(index int, page *dto.DataPointListItem) error,
+) error {
+	if items == nil {
+		return errNilQueryItems
+	}
+	defaults := dataPointsDefaults{
+		Start:                startTime,
+		End:                  endTime,
+		Limit:                limit,
+		Aggregates:           aggregates,
+		Granularity:          granularity,
+		IncludeOutsidePoints: includeOutsidePoints,
+		TimeZone:             timeZone,
+		IgnoreUnknownIds:     ignoreUnknownIds,
+	}
+	if err := validateDataPointsQuery(*items, defaults, nil, false); err != nil {
+		return err
+	}
+	return t.retrieveAllPages(*items, defaults, fn)
+}
+
+// retrieveAllState tracks the paging progress of a single query item.
+type retrieveAllState struct {
+	index     int
+	query     dto.DataPointsQueryItem
+	remaining int64
+	aggregate bool
+}
+
+// retrieveAllPages fetches every page of every item and passes the pages to
+// fn in order for each item. Pages of different items may be interleaved.
+func (t *TimeSeries) retrieveAllPages(
+	items []dto.DataPointsQueryItem,
+	defaults dataPointsDefaults,
+	fn func(index int, page *dto.DataPointListItem) error,
+) error {
+	var defaultLimit int64
+	if defaults.Limit != nil {
+		defaultLimit = *defaults.Limit
+	}
+	// Per-item limits are set on every page instead
+	defaults.Limit = nil
+	defaultAggregates := defaults.Aggregates != nil && len(*defaults.Aggregates) > 0
+
+	pending := make([]*retrieveAllState, 0, len(items))
+	for i := range items {
+		state := &retrieveAllState{
+			index:     i,
+			query:     items[i],
+			remaining: items[i].Limit,
+			aggregate: len(items[i].Aggregates) > 0 || defaultAggregates,
+		}
+		if state.remaining == 0 {
+			state.remaining = defaultLimit
+		}
+		pending = append(pending, state)
+	}
+
+	for len(pending) > 0 {
+		var next []*retrieveAllState
+		for _, group := range groupRetrieveAllStates(pending) {
+			budget := int64(retrieveRawDatapointsLimit)
+			if group[0].aggregate {
+				budget = retrieveAggregateDatapointsLimit
+			}
+			pageSize := max(1, budget/int64(len(group)))
+
+			queries := make([]dto.DataPointsQueryItem, len(group))
+			for i, state := range group {
+				queries[i] = state.query
+				queries[i].Limit = pageSize
+				if state.remaining > 0 {
+					queries[i].Limit = min(pageSize, state.remaining)
+				}
+			}
+
+			response, err := t.retrieveDataPage(&queries, defaults)
+			if err != nil {
+				return err
+			}
+
+			// Unknown items are missing from the response when ignoreUnknownIds is set
+			k := 0
+			for _, state := range group {
+				if k >= len(response.Items) || !matchesQueryItem(&state.query, response.Items[k]) {
+					continue
+				}
+				page := response.Items[k]
+				k++
+
+				if err := fn(state.index, page); err != nil {
+					return err
+				}
+				if state.remaining > 0 {
+					state.remaining -= int64(page.Len())
+					if state.remaining <= 0 {
+						continue
+					}
+				}
+				if page.NextCursor == "" {
+					continue
+				}
+				state.query.Cursor = page.NextCursor
+				next = append(next, state)
+			}
+			if k != len(response.Items) {
+				return fmt.Errorf("failed to match %d datapoint items to the query", len(response.Items)-k)
+			}
+		}
+		pending = next
+	}
+
+	return nil
+}
+
+// groupRetrieveAllStates splits states into request-sized groups that do not
+// mix raw and aggregate queries.
+func groupRetrieveAllStates(states []*retrieveAllState) [][]*retrieveAllState {
+	var raw, aggregate []*retrieveAllState
+	for _, state := range states {
+		if state.aggregate {
+			aggregate = append(aggregate, state)
+		} else {
+			raw = append(raw, state)
+		}
+	}
+
+	var groups [][]*retrieveAllState
+	for _, states := range [][]*retrieveAllState{raw, aggregate} {
+		for start := 0; start < len(states); start += retrieveDataItemsLimit {
+			groups = append(groups, states[start:min(start+retrieveDataItemsLimit, len(states))])
+		}
+	}
+	return groups
+}
+
+// matchesQueryItem reports whether item is the response to query.
+func matchesQueryItem(query *dto.DataPointsQueryItem, item *dto.DataPointListItem) bool {
+	switch {
+	case query.InstanceId != nil:
+		return item.GetInstanceId().GetSpace() == query.InstanceId.GetSpace() &&
+			item.GetInstanceId().GetExternalId() == query.InstanceId.GetExternalId()
+	case query.ExternalId != "":
+		return item.GetExternalId() == query.ExternalId
+	default:
+		return item.GetId() == query.Id
+	}
+}
+
+// appendDatapoints appends the datapoints of src to dst, skipping datapoints
+// that are not after the last datapoint already in dst.
+func appendDatapoints(dst, src *dto.DataPointListItem) {
+	switch s := src.DatapointType.(type) {
+	case *dto.DataPointListItem_NumericDatapoints:
+		d, ok := dst.DatapointType.(*dto.DataPointListItem_NumericDatapoints)
+		if !ok || d.NumericDatapoints == nil {
+			dst.DatapointType = s
+			return
+		}
+		points := d.NumericDatapoints.Datapoints
+		for _, point := range s.NumericDatapoints.GetDatapoints() {
+			if len(points) == 0 || point.Timestamp > points[len(points)-1].Timestamp {
+				points = append(points, point)
+			}
+		}
+		d.NumericDatapoints.Datapoints = points
+	case *dto.DataPointListItem_StringDatapoints:
+		d, ok := dst.DatapointType.(*dto.DataPointListItem_StringDatapoints)
+		if !ok || d.StringDatapoints == nil {
+			dst.DatapointType = s
+			return
+		}
+		points := d.StringDatapoints.Datapoints
+		for _, point := range s.StringDatapoints.GetDatapoints() {
+			if len(points) == 0 || point.Timestamp > points[len(points)-1].Timestamp {
+				points = append(points, point)
+			}
+		}
+		d.StringDatapoints.Datapoints = points
+	case *dto.DataPointListItem_AggregateDatapoints:
+		d, ok := dst.DatapointType.(*dto.DataPointListItem_AggregateDatapoints)
+		if !ok || d.AggregateDatapoints == nil {
+			dst.DatapointType = s
+			return
+		}
+		points := d.AggregateDatapoints.Datapoints
+		for _, point := range s.AggregateDatapoints.GetDatapoints() {
+			if len(points) == 0 || point.Timestamp > points[len(points)-1].Timestamp {
+				points = append(points, point)
+			}
+		}
+		d.AggregateDatapoints.Datapoints = points
+	}
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// fakeDatapointsServer serves raw numeric datapoints for the series it holds,
+// paging with cursors like /timeseries/data/list does
+type fakeDatapointsServer struct {
+	mu       sync.Mutex
+	series   map[string][]int64
+	requests []fakeDatapointsRequest
+	// countStatus, when set, is the status code of every aggregate request
+	countStatus int
+	// bad holds the timestamps of datapoints with a bad status. Like CDF,
+	// the server leaves them out unless ignoreBadDataPoints is false.
+	bad map[int64]bool
+}
+
+type fakeDatapointsRequest struct {
+	Items               []dto.DataPointsQueryItem `json:"items"`
+	Limit               *int64                    `json:"limit"`
+	IgnoreUnknownIds    bool                      `json:"ignoreUnknownIds"`
+	IgnoreBadDataPoints *bool                     `json:"ignoreBadDataPoints"`
+}
+
+func (f *fakeDatapointsServer) handler(t *testing.T) http.HandlerFunc {
+	t.Helper()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("Failed to open gzip body: %v", err)
+			return
+		}
+		var request fakeDatapointsRequest
+		if err := json.NewDecoder(gz).Decode(&request); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+			return
+		}
+
+		f.mu.Lock()
+		f.requests = append(f.requests, request)
+		f.mu.Unlock()
+
+		if f.countStatus != 0 && len(request.Items) > 0 && len(request.Items[0].Aggregates) > 0 {
+			w.WriteHeader(f.countStatus)
+			fmt.Fprintf(w, `{"error":{"code":%d,"message":"Aggregates are not supported"}}`, f.countStatus)
+			return
+		}
+
+		response := &dto.DataPointListResponse{}
+		for _, query := range request.Items {
+			timestamps, ok := f.series[query.ExternalId]
+			if !ok {
+				if request.IgnoreUnknownIds {
+					continue
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			start, end := int64(0), int64(math.MaxInt64)
+			if query.Start != "" {
+				start, _ = strconv.ParseInt(query.Start, 10, 64)
+			}
+			if query.End != "" {
+				end, _ = strconv.ParseInt(query.End, 10, 64)
+			}
+			if query.Cursor != "" {
+				start, _ = strconv.ParseInt(query.Cursor, 10, 64)
+			}
+			limit := query.Limit
+			if limit == 0 {
+				limit = 100
+			}
+			if query.IgnoreBadDataPoints || request.IgnoreBadDataPoints == nil || *request.IgnoreBadDataPoints {
+				var good []int64
+				for _, ts := range timestamps {
+					if !f.bad[ts] {
+						good = append(good, ts)
+					}
+				}
+				timestamps = good
+			}
+
+			item := &dto.DataPointListItem{ExternalId: query.ExternalId}
+			if len(query.Aggregates) > 0 {
+				item.DatapointType = &dto.DataPointListItem_AggregateDatapoints{
+					AggregateDatapoints: &dto.AggregateDatapoints{Datapoints: fakeCounts(timestamps, start, end, string(query.Granularity))},
+				}
+				response.Items = append(response.Items, item)
+				continue
+			}
+			var points []*dto.NumericDatapoint
+			for _, ts := range timestamps {
+				if ts < start || ts >= end {
+					continue
+				}
+				if int64(len(points)) == limit {
+					item.NextCursor = strconv.FormatInt(ts, 10)
+					break
+				}
+				points = append(points, &dto.NumericDatapoint{Timestamp: ts, Value: float64(ts)})
+			}
+			item.DatapointType = &dto.DataPointListItem_NumericDatapoints{
+				NumericDatapoints: &dto.NumericDatapoints{Datapoints: points},
+			}
+			response.Items = append(response.Items, item)
+		}
+		writeProto(t, w, response)
+	}
+}
+
+// fakeCounts computes count aggregates like CDF: start is rounded down to a
+// whole granularity unit, the buckets follow from there, and the last bucket
+// counts its whole width even past end
+func fakeCounts(timestamps []int64, start, end int64, granularity string) []*dto.AggregateDatapoint {
+	units := map[byte]int64{'s': 1000, 'm': 60000, 'h': 3600000, 'd': 86400000}
+	unit := units[granularity[len(granularity)-1]]
+	multiple, _ := strconv.ParseInt(granularity[:len(granularity)-1], 10, 64)
+	width := multiple * unit
+	origin := start / unit * unit
+
+	var buckets []*dto.AggregateDatapoint
+	for _, ts := range timestamps {
+		if ts < origin {
+			continue
+		}
+		bucketStart := origin + (ts-origin)/width*width
+		if bucketStart >= end {
+			continue
+		}
+		if len(buckets) == 0 || buckets[len(buckets)-1].Timestamp != bucketStart {
+			buckets = append(buckets, &dto.AggregateDatapoint{Timestamp: bucketStart})
+		}
+		buckets[len(buckets)-1].Count++
+	}
+	return buckets
+}
+
+func sequence(n int) []int64 {
+	timestamps := make([]int64, n)
+	for i := range timestamps {
+		timestamps[i] = int64(i)
+	}
+	return timestamps
+}
+
+func TestTimeSeries_RetrieveAll(t *testing.T) {
+	fake := &fakeDatapointsServer{series: map[string][]int64{
+		"big":   sequence(250000),
+		"small": sequence(10),
+	}}
+	client := newTestClient(t, fake.handler(t))
+
+	ignoreUnknownIds := true
+	items := []dto.DataPointsQueryItem{
+		{ExternalId: "big"},
+		{ExternalId: "unknown"},
+		{ExternalId: "small"},
+	}
+	response, err := client.TimeSeries.RetrieveAll(&items, nil, nil, nil, nil, nil, nil, nil, &ignoreUnknownIds)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(response.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(response.Items))
+	}
+	big := response.Items[0].GetNumericDatapoints().GetDatapoints()
+	if response.Items[0].ExternalId != "big" || len(big) != 250000 {
+		t.Errorf("Expected 250000 datapoints for big, got %d", len(big))
+	}
+	for i, point := range big {
+		if point.Timestamp != int64(i) {
+			t.Fatalf("Datapoint %d has timestamp %d", i, point.Timestamp)
+		}
+	}
+	if len(response.Items[1].GetNumericDatapoints().GetDatapoints()) != 10 {
+		t.Errorf("Expected 10 datapoints for small")
+	}
+	if response.Items[0].NextCursor != "" {
+		t.Errorf("Expected no cursor after fetching the whole range")
+	}
+
+	for _, request := range fake.requests {
+		var total int64
+		for _, item := range request.Items {
+			total += item.Limit
+		}
+		if total > retrieveRawDatapointsLimit {
+			t.Errorf("Request asked for %d datapoints", total)
+		}
+	}
+}
+
+func TestTimeSeries_RetrieveAll_limit(t *testing.T) {
+	fake := &fakeDatapointsServer{series: map[string][]int64{"big": sequence(250000)}}
+	client := newTestClient(t, fake.handler(t))
+
+	items := []dto.DataPointsQueryItem{{ExternalId: "big", Limit: 150000}}
+	response, err := client.TimeSeries.RetrieveAll(&items, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if n := len(response.Items[0].GetNumericDatapoints().GetDatapoints()); n != 150000 {
+		t.Errorf("Expected 150000 datapoints, got %d", n)
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("Expected 2 requests, got %d", len(fake.requests))
+	}
+	if response.Items[0].NextCursor == "" {
+		t.Error("Expected cursor to be kept when stopping at the limit")
+	}
+}
+
+func TestTimeSeries_RetrieveAllFunc(t *testing.T) {
+	fake := &fakeDatapointsServer{series: map[string][]int64{"big": sequence(250000)}}
+	client := newTestClient(t, fake.handler(t))
+
+	items := []dto.DataPointsQueryItem{{ExternalId: "big"}}
+	pages, total := 0, 0
+	err := client.TimeSeries.RetrieveAllFunc(&items, nil, nil, nil, nil, nil, nil, nil, nil,
+		func(index int, page *dto.DataPointListItem) error {
+			if index != 0 {
+				t.Errorf("Unexpected index %d", index)
+			}
+			if page.Len() > 0 && page.Timestamps()[0] != int64(total) {
+				t.Errorf("Page %d starts at %d, want %d", pages, page.Timestamps()[0], total)
+			}
+			pages++
+			total += page.Len()
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pages != 3 || total != 250000 {
+		t.Errorf("Expected 250000 datapoints in 3 pages, got %d in %d", total, pages)
+	}
+
+	stop := errors.New("stop")
+	err = client.TimeSeries.RetrieveAllFunc(&items, nil, nil, nil, nil, nil, nil, nil, nil,
+		func(int, *dto.DataPointListItem) error { return stop })
+	if err != stop {
+		t.Errorf("Expected the callback error, got %v", err)
+	}
+}
+
+func TestTimeSeries_RetrieveAll_nilItems(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request to be sent")
+	})
+
+	if _, err := client.TimeSeries.RetrieveAll(nil, nil, nil, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error from RetrieveAll")
+	}
+	err := client.TimeSeries.RetrieveAllFunc(nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		func(int, *dto.DataPointListItem) error { return nil })
+	if err == nil {
+		t.Error("Expected an error from RetrieveAllFunc")
+	}
+	if _, err := client.TimeSeries.RetrieveData(nil, nil, nil, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error from RetrieveData")
+	}
+}
+
+func TestTimeSeries_RetrieveAll_unmatchedItems(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeProto(t, w, &dto.DataPointListResponse{Items: []*dto.DataPointListItem{
+			{ExternalId: "a"},
+			{ExternalId: "surprise"},
+		}})
+	})
+
+	items := []dto.DataPointsQueryItem{{ExternalId: "a"}}
+	if _, err := client.TimeSeries.RetrieveAll(&items, nil, nil, nil, nil, nil, nil, nil, nil); err == nil ||
+		!strings.Contains(err.Error(), "failed to match 1 datapoint items") {
+		t.Errorf("Expected the unmatched item to be rejected, got %v", err)
+	}
+}
+
+func TestAppendDatapoints_skipsDuplicates(t *testing.T) {
+	dst := &dto.DataPointListItem{DatapointType: &dto.DataPointListItem_StringDatapoints{
+		StringDatapoints: &dto.StringDatapoints{Datapoints: []*dto.StringDatapoint{{Timestamp: 1}, {Timestamp: 2}}},
+	}}
+	src := &dto.DataPointListItem{DatapointType: &dto.DataPointListItem_StringDatapoints{
+		StringDatapoints: &dto.StringDatapoints{Datapoints: []*dto.StringDatapoint{{Timestamp: 2}, {Timestamp: 3}}},
+	}}
+
+	appendDatapoints(dst, src)
+
+	points := dst.GetStringDatapoints().GetDatapoints()
+	if len(points) != 3 || points[2].Timestamp != 3 {
+		t.Errorf("Unexpected datapoints %v", points)
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// ValidateDataPointsQuery checks a datapoints query against CDF's rules
+// before it is sent. Aggregates must be known and come with a valid
+// granularity, a granularity needs aggregates, includeOutsidePoints cannot be
+// combined with aggregates, and limits may not exceed 100000 raw or 10000
+// aggregate datapoints. The top-level arguments are the request defaults,
+// as in RetrieveData.
+//
+// series are optional time series known to be queried. They let the check
+// reject aggregates on string time series, which the query alone cannot
+// reveal.
+func ValidateDataPointsQuery(
+	items []dto.DataPointsQueryItem,
+	limit *int64,
+	aggregates *[]string,
+	granularity *string,
+	includeOutsidePoints *bool,
+	series []dto.TimeSeries,
+) error {
+	return validateDataPointsQuery(items, dataPointsDefaults{
+		Limit:                limit,
+		Aggregates:           aggregates,
+		Granularity:          granularity,
+		IncludeOutsidePoints: includeOutsidePoints,
+	}, series, true)
+}
+
+// validateDataPointsQuery implements ValidateDataPointsQuery. checkLimits is
+// false for callers that page, where a limit is the total to fetch.
+func validateDataPointsQuery(
+	items []dto.DataPointsQueryItem,
+	defaults dataPointsDefaults,
+	series []dto.TimeSeries,
+	checkLimits bool,
+) error {
+	var defaultAggregates []string
+	if defaults.Aggregates != nil {
+		defaultAggregates = *defaults.Aggregates
+	}
+	var defaultGranularity string
+	if defaults.Granularity != nil {
+		defaultGranularity = *defaults.Granularity
+	}
+	var defaultLimit int64
+	if defaults.Limit != nil {
+		defaultLimit = *defaults.Limit
+	}
+	defaultOutsidePoints := defaults.IncludeOutsidePoints != nil && *defaults.IncludeOutsidePoints
+
+	if err := validateAggregates(defaultAggregates, defaultGranularity); err != nil {
+		return fmt.Errorf("invalid datapoints query: %w", err)
+	}
+
+	stringSeries := make(map[string]bool)
+	for i := range series {
+		if !series[i].IsString {
+			continue
+		}
+		stringSeries[formatIdentity(dto.Identity{Id: series[i].Id})] = true
+		if series[i].ExternalId != "" {
+			stringSeries[formatIdentity(dto.Identity{ExternalId: series[i].ExternalId})] = true
+		}
+		if series[i].InstanceId.Space != "" {
+			stringSeries[formatIdentity(dto.Identity{InstanceId: &dto.InstanceId{
+				Space:      series[i].InstanceId.Space,
+				ExternalId: series[i].InstanceId.ExternalId,
+			}})] = true
+		}
+	}
+
+	for i := range items {
+		item := &items[i]
+		if err := validateDataPointsQueryItem(item, defaultAggregates, defaultGranularity, defaultLimit, defaultOutsidePoints, checkLimits); err != nil {
+			return fmt.Errorf("invalid datapoints query item %d: %w", i, err)
+		}
+		id := formatIdentity(dto.Identity{Id: item.Id, ExternalId: item.ExternalId, InstanceId: item.InstanceId})
+		if stringSeries[id] && (len(item.Aggregates) > 0 || len(defaultAggregates) > 0) {
+			return fmt.Errorf("invalid datapoints query item %d: aggregates are not supported on string time series %s", i, id)
+		}
+	}
+	return nil
+}
+
+func validateDataPointsQueryItem(
+	item *dto.DataPointsQueryItem,
+	defaultAggregates []string,
+	defaultGranularity string,
+	defaultLimit int64,
+	defaultOutsidePoints bool,
+	checkLimits bool,
+) error {
+	if item.Id == 0 && item.ExternalId == "" && item.InstanceId == nil {
+		return errors.New("no id, externalId or instanceId")
+	}
+	if err := validateAggregates(item.Aggregates, item.Granularity); err != nil {
+		return err
+	}
+
+	aggregates := item.Aggregates
+	if len(aggregates) == 0 {
+		aggregates = defaultAggregates
+	}
+	granularity := item.Granularity
+	if granularity == "" {
+		granularity = defaultGranularity
+	}
+	limit := item.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	isAggregate := len(aggregates) > 0
+
+	switch {
+	case isAggregate && granularity == "":
+		return errors.New("aggregates require a granularity")
+	case !isAggregate && granularity != "":
+		return fmt.Errorf("granularity %s requires aggregates", granularity)
+	case isAggregate && (item.IncludeOutsidePoints || defaultOutsidePoints):
+		return errors.New("includeOutsidePoints cannot be combined with aggregates")
+	case limit < 0:
+		return fmt.Errorf("limit %d is negative", limit)
+	}
+
+	if checkLimits {
+		maxLimit, kind := int64(retrieveRawDatapointsLimit), "raw"
+		if isAggregate {
+			maxLimit, kind = retrieveAggregateDatapointsLimit, "aggregate"
+		}
+		if limit > maxLimit {
+			return fmt.Errorf("limit %d exceeds the maximum of %d %s datapoints", limit, maxLimit, kind)
+		}
+	}
+	return nil
+}
+
+// validateAggregates checks that every aggregate is known and that the
+// granularity, when set, is valid.
+func validateAggregates(aggregates []string, granularity string) error {
+	for _, aggregate := range aggregates {
+		if !dto.Aggregate(aggregate).Valid() {
+			return fmt.Errorf("unknown aggregate %q", aggregate)
+		}
+	}
+	if granularity != "" {
+		return dto.Granularity(granularity).Validate()
+	}
+	return nil
+}
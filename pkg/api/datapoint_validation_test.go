@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestValidateDataPointsQuery(t *testing.T) {
+	average := []string{dto.AggregateAverage}
+	hour := "1h"
+	outside := true
+	series := []dto.TimeSeries{{Id: 2, ExternalId: "state", IsString: true}}
+
+	tests := []struct {
+		name        string
+		items       []dto.DataPointsQueryItem
+		aggregates  *[]string
+		granularity *string
+		outside     *bool
+		expectedErr string
+	}{
+		{
+			name:  "raw",
+			items: []dto.DataPointsQueryItem{{Id: 1, Limit: 100000}},
+		},
+		{
+			name:  "aggregates",
+			items: []dto.DataPointsQueryItem{{Id: 1, Aggregates: average, Granularity: "15m", Limit: 10000}},
+		},
+		{
+			name:        "default aggregates",
+			items:       []dto.DataPointsQueryItem{{Id: 1}},
+			aggregates:  &average,
+			granularity: &hour,
+		},
+		{
+			name:        "missing identity",
+			items:       []dto.DataPointsQueryItem{{}},
+			expectedErr: "no id, externalId or instanceId",
+		},
+		{
+			name:        "misspelled aggregate",
+			items:       []dto.DataPointsQueryItem{{Id: 1, Aggregates: []string{"avg"}, Granularity: "1h"}},
+			expectedErr: `unknown aggregate "avg"`,
+		},
+		{
+			name:        "spelled out granularity",
+			items:       []dto.DataPointsQueryItem{{Id: 1, Aggregates: average, Granularity: "1hour"}},
+			expectedErr: "invalid granularity",
+		},
+		{
+			name:        "granularity without aggregates",
+			items:       []dto.DataPointsQueryItem{{Id: 1, Granularity: "1h"}},
+			expectedErr: "requires aggregates",
+		},
+		{
+			name:        "aggregates without granularity",
+			items:       []dto.DataPointsQueryItem{{Id: 1, Aggregates: average}},
+			expectedErr: "aggregates require a granularity",
+		},
+		{
+			name:        "outside points with aggregates",
+			items:       []dto.DataPointsQueryItem{{Id: 1}},
+			aggregates:  &average,
+			granularity: &hour,
+			outside:     &outside,
+			expectedErr: "includeOutsidePoints",
+		},
+		{
+			name:        "raw limit",
+			items:       []dto.DataPointsQueryItem{{Id: 1, Limit: 100001}},
+			expectedErr: "exceeds the maximum of 100000 raw",
+		},
+		{
+			name:        "aggregate limit",
+			items:       []dto.DataPointsQueryItem{{Id: 1, Aggregates: average, Granularity: "1h", Limit: 10001}},
+			expectedErr: "exceeds the maximum of 10000 aggregate",
+		},
+		{
+			name:        "string series",
+			items:       []dto.DataPointsQueryItem{{ExternalId: "state", Aggregates: average, Granularity: "1h"}},
+			expectedErr: "not supported on string time series",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDataPointsQuery(tt.items, nil, tt.aggregates, tt.granularity, tt.outside, series)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestTimeSeries_RetrieveData_invalidQuery(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request, got %s %s", r.Method, r.URL.Path)
+	})
+
+	items := []dto.DataPointsQueryItem{{Id: 1, Aggregates: []string{"avg"}, Granularity: "1h"}}
+	if _, err := client.TimeSeries.RetrieveData(&items, nil, nil, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Expected an error for an unknown aggregate")
+	}
+}
@@ -0,0 +1,408 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+const (
+	// insertDatapointsLimit is the maximum number of datapoints per insert request.
+	insertDatapointsLimit = 100000
+	// insertItemsLimit is the maximum number of time series per insert request.
+	insertItemsLimit = 10000
+	// insertStringValueBytesLimit is the maximum size of a single string datapoint.
+	insertStringValueBytesLimit = 1023
+	// insertStringBytesLimit is the maximum total size of string values per insert request.
+	insertStringBytesLimit = 1000000
+)
+
+// DatapointWriterConfig controls how a DatapointWriter batches and sends
+// datapoints. Zero values are replaced with defaults, and request limits are
+// capped at the CDF maximums.
+type DatapointWriterConfig struct {
+	// MaxDatapointsPerRequest defaults to 100000.
+	MaxDatapointsPerRequest int
+	// MaxItemsPerRequest defaults to 10000.
+	MaxItemsPerRequest int
+	// MaxStringBytesPerRequest defaults to 1000000.
+	MaxStringBytesPerRequest int
+	// FlushSize is the number of buffered datapoints that triggers a flush.
+	// It defaults to MaxDatapointsPerRequest.
+	FlushSize int
+	// FlushInterval flushes buffered datapoints periodically. Zero disables
+	// interval flushing.
+	FlushInterval time.Duration
+	// MaxConcurrentRequests defaults to 4.
+	MaxConcurrentRequests int
+	// MaxRetries is the number of retries for a failed request. It defaults
+	// to 3; use a negative value to disable retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled on every
+	// following attempt. It defaults to 500ms.
+	RetryBackoff time.Duration
+}
+
+// SeriesWriteReport summarizes what a DatapointWriter did for one time series.
+type SeriesWriteReport struct {
+	Identity  dto.Identity
+	Written   int
+	Failed    int
+	LastError error
+}
+
+// DatapointWriterReport is returned by DatapointWriter.Close. Series is keyed
+// by the time series identifier, e.g. "externalId=my-series".
+type DatapointWriterReport struct {
+	Series  map[string]*SeriesWriteReport
+	Written int
+	Failed  int
+}
+
+// DatapointWriter buffers datapoints for many time series and writes them
+// with InsertData in batches that respect CDF's request limits. It is safe
+// for concurrent use.
+//
+// Batches are sent concurrently, so datapoints with the same timestamp added
+// in different flushes may be written in any order.
+type DatapointWriter struct {
+	timeSeries *TimeSeries
+	config     DatapointWriterConfig
+
+	mu       sync.Mutex
+	buffers  map[string]*seriesBuffer
+	order    []string
+	buffered int
+	report   DatapointWriterReport
+	closed   bool
+
+	// pending counts the batches taken from the buffer whose request has
+	// not completed yet. It is guarded by mu, and idle is signalled when it
+	// drops to zero.
+	pending   int
+	idle      *sync.Cond
+	semaphore chan struct{}
+	done      chan struct{}
+	ticker    sync.WaitGroup
+}
+
+type seriesBuffer struct {
+	id       dto.Identity
+	isString bool
+	numeric  []*dto.NumericDatapoint
+	strings  []*dto.StringDatapoint
+}
+
+// writeBatch is a single insert request together with the number of
+// datapoints it carries for each time series.
+type writeBatch struct {
+	items  []*dto.DataPointInsertionItem
+	keys   []string
+	counts []int
+}
+
+// NewDatapointWriter creates a writer that inserts datapoints through t.
+// Close must be called to flush the remaining datapoints and stop the
+// interval flushing.
+func NewDatapointWriter(t *TimeSeries, config DatapointWriterConfig) *DatapointWriter {
+	config.MaxDatapointsPerRequest = limitOrDefault(config.MaxDatapointsPerRequest, insertDatapointsLimit)
+	config.MaxItemsPerRequest = limitOrDefault(config.MaxItemsPerRequest, insertItemsLimit)
+	config.MaxStringBytesPerRequest = limitOrDefault(config.MaxStringBytesPerRequest, insertStringBytesLimit)
+	if config.FlushSize <= 0 {
+		config.FlushSize = config.MaxDatapointsPerRequest
+	}
+	if config.MaxConcurrentRequests <= 0 {
+		config.MaxConcurrentRequests = 4
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+
+	w := &DatapointWriter{
+		timeSeries: t,
+		config:     config,
+		buffers:    make(map[string]*seriesBuffer),
+		report:     DatapointWriterReport{Series: make(map[string]*SeriesWriteReport)},
+		semaphore:  make(chan struct{}, config.MaxConcurrentRequests),
+		done:       make(chan struct{}),
+	}
+	w.idle = sync.NewCond(&w.mu)
+
+	if config.FlushInterval > 0 {
+		w.ticker.Add(1)
+		go w.flushPeriodically()
+	}
+
+	return w
+}
+
+func limitOrDefault(value int, limit int) int {
+	if value <= 0 || value > limit {
+		return limit
+	}
+	return value
+}
+
+// AddNumeric buffers numeric datapoints for the time series identified by id.
+func (w *DatapointWriter) AddNumeric(id dto.Identity, datapoints ...*dto.NumericDatapoint) error {
+	w.mu.Lock()
+	buffer, err := w.bufferLocked(id, false)
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	buffer.numeric = append(buffer.numeric, datapoints...)
+	flush := w.addBufferedLocked(len(datapoints))
+	w.mu.Unlock()
+
+	if flush {
+		w.flushBuffered()
+	}
+	return nil
+}
+
+// AddString buffers string datapoints for the time series identified by id.
+// Values longer than 1023 bytes are rejected.
+func (w *DatapointWriter) AddString(id dto.Identity, datapoints ...*dto.StringDatapoint) error {
+	for _, datapoint := range datapoints {
+		if len(datapoint.GetValue()) > insertStringValueBytesLimit {
+			return fmt.Errorf("string datapoint at %d for %s exceeds %d bytes",
+				datapoint.GetTimestamp(), formatIdentity(id), insertStringValueBytesLimit)
+		}
+	}
+
+	w.mu.Lock()
+	buffer, err := w.bufferLocked(id, true)
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	buffer.strings = append(buffer.strings, datapoints...)
+	flush := w.addBufferedLocked(len(datapoints))
+	w.mu.Unlock()
+
+	if flush {
+		w.flushBuffered()
+	}
+	return nil
+}
+
+func (w *DatapointWriter) bufferLocked(id dto.Identity, isString bool) (*seriesBuffer, error) {
+	if w.closed {
+		return nil, errors.New("datapoint writer is closed")
+	}
+	if id.Id == 0 && id.ExternalId == "" && id.InstanceId == nil {
+		return nil, errors.New("datapoints must be addressed by id, externalId or instanceId")
+	}
+
+	key := formatIdentity(id)
+	buffer, ok := w.buffers[key]
+	if !ok {
+		buffer = &seriesBuffer{id: id, isString: isString}
+		w.buffers[key] = buffer
+		w.order = append(w.order, key)
+	}
+	if buffer.isString != isString {
+		return nil, fmt.Errorf("cannot mix numeric and string datapoints for %s", key)
+	}
+	return buffer, nil
+}
+
+func (w *DatapointWriter) addBufferedLocked(count int) bool {
+	w.buffered += count
+	return w.buffered >= w.config.FlushSize
+}
+
+// Flush sends all buffered datapoints and waits for every in-flight request
+// to complete.
+func (w *DatapointWriter) Flush() {
+	w.flushBuffered()
+
+	w.mu.Lock()
+	for w.pending > 0 {
+		w.idle.Wait()
+	}
+	w.mu.Unlock()
+}
+
+// Close flushes the remaining datapoints, waits for in-flight requests and
+// returns the per-series report. The returned error is non-nil if any
+// datapoints failed to be written.
+func (w *DatapointWriter) Close() (DatapointWriterReport, error) {
+	w.mu.Lock()
+	alreadyClosed := w.closed
+	w.closed = true
+	w.mu.Unlock()
+
+	if !alreadyClosed {
+		close(w.done)
+		w.ticker.Wait()
+	}
+	w.Flush()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.report.Failed > 0 {
+		return w.report, fmt.Errorf("failed to write %d datapoints", w.report.Failed)
+	}
+	return w.report, nil
+}
+
+func (w *DatapointWriter) flushPeriodically() {
+	defer w.ticker.Done()
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flushBuffered()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// flushBuffered drains the buffer into batches and dispatches them. It blocks
+// while MaxConcurrentRequests requests are in flight.
+func (w *DatapointWriter) flushBuffered() {
+	w.mu.Lock()
+	batches := w.takeBatchesLocked()
+	w.pending += len(batches)
+	w.mu.Unlock()
+
+	for _, batch := range batches {
+		w.semaphore <- struct{}{}
+		go func(batch writeBatch) {
+			defer func() { <-w.semaphore }()
+			w.send(batch)
+		}(batch)
+	}
+}
+
+// takeBatchesLocked splits the buffered datapoints into request-sized
+// batches and empties the buffer.
+func (w *DatapointWriter) takeBatchesLocked() []writeBatch {
+	var batches []writeBatch
+	var current writeBatch
+	points, stringBytes := 0, 0
+
+	startNewBatch := func() {
+		if len(current.items) > 0 {
+			batches = append(batches, current)
+		}
+		current = writeBatch{}
+		points, stringBytes = 0, 0
+	}
+
+	for _, key := range w.order {
+		buffer := w.buffers[key]
+		if buffer.isString {
+			remaining := buffer.strings
+			for len(remaining) > 0 {
+				if len(current.items) == w.config.MaxItemsPerRequest || points == w.config.MaxDatapointsPerRequest {
+					startNewBatch()
+				}
+				n := 0
+				for n < len(remaining) && points+n < w.config.MaxDatapointsPerRequest {
+					size := len(remaining[n].GetValue())
+					// A single oversized value still gets a request of its own
+					if stringBytes+size > w.config.MaxStringBytesPerRequest && points+n > 0 {
+						break
+					}
+					stringBytes += size
+					n++
+				}
+				if n == 0 {
+					startNewBatch()
+					continue
+				}
+				current.items = append(current.items, dto.NewStringInsertionItem(buffer.id, remaining[:n]))
+				current.keys = append(current.keys, key)
+				current.counts = append(current.counts, n)
+				points += n
+				remaining = remaining[n:]
+			}
+			continue
+		}
+
+		remaining := buffer.numeric
+		for len(remaining) > 0 {
+			if len(current.items) == w.config.MaxItemsPerRequest || points == w.config.MaxDatapointsPerRequest {
+				startNewBatch()
+			}
+			n := min(len(remaining), w.config.MaxDatapointsPerRequest-points)
+			current.items = append(current.items, dto.NewNumericInsertionItem(buffer.id, remaining[:n]))
+			current.keys = append(current.keys, key)
+			current.counts = append(current.counts, n)
+			points += n
+			remaining = remaining[n:]
+		}
+	}
+	startNewBatch()
+
+	for _, key := range w.order {
+		buffer := w.buffers[key]
+		if _, ok := w.report.Series[key]; !ok {
+			w.report.Series[key] = &SeriesWriteReport{Identity: buffer.id}
+		}
+	}
+	w.buffers = make(map[string]*seriesBuffer)
+	w.order = nil
+	w.buffered = 0
+
+	return batches
+}
+
+// send inserts a batch, retrying network errors, throttling and server
+// errors with exponential backoff, records the outcome in the report and
+// marks the batch as done.
+func (w *DatapointWriter) send(batch writeBatch) {
+	var err error
+	backoff := w.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err = w.timeSeries.InsertData(batch.items)
+		if err == nil || attempt >= w.config.MaxRetries || !isRetryable(err) {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending--
+	if w.pending == 0 {
+		w.idle.Broadcast()
+	}
+	for i, key := range batch.keys {
+		series := w.report.Series[key]
+		if err != nil {
+			series.Failed += batch.counts[i]
+			series.LastError = err
+			w.report.Failed += batch.counts[i]
+		} else {
+			series.Written += batch.counts[i]
+			w.report.Written += batch.counts[i]
+		}
+	}
+}
+
+// isRetryable reports whether a failed request may succeed when retried.
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	// Transport errors (connection resets, timeouts) are transient
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// insertRecorder decodes and records the insertion requests it receives
+type insertRecorder struct {
+	mu       sync.Mutex
+	requests []*dto.DataPointInsertionRequest
+}
+
+func (r *insertRecorder) decode(t *testing.T, req *http.Request) *dto.DataPointInsertionRequest {
+	t.Helper()
+
+	gz, err := gzip.NewReader(req.Body)
+	if err != nil {
+		t.Errorf("Failed to open gzip body: %v", err)
+		return nil
+	}
+	body, _ := io.ReadAll(gz)
+	var insertion dto.DataPointInsertionRequest
+	if err := proto.Unmarshal(body, &insertion); err != nil {
+		t.Errorf("Failed to decode protobuf body: %v", err)
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, &insertion)
+	return &insertion
+}
+
+func countDatapoints(request *dto.DataPointInsertionRequest) int {
+	total := 0
+	for _, item := range request.GetItems() {
+		total += len(item.GetNumericDatapoints().GetDatapoints()) + len(item.GetStringDatapoints().GetDatapoints())
+	}
+	return total
+}
+
+func TestDatapointWriter_splitsRequests(t *testing.T) {
+	recorder := &insertRecorder{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		recorder.decode(t, r)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	writer := NewDatapointWriter(&client.TimeSeries, DatapointWriterConfig{
+		MaxDatapointsPerRequest: 10,
+		MaxItemsPerRequest:      2,
+		FlushSize:               1000,
+		MaxConcurrentRequests:   2,
+	})
+
+	for s := 0; s < 3; s++ {
+		id := dto.Identity{ExternalId: fmt.Sprintf("ts-%d", s)}
+		for i := 0; i < 7; i++ {
+			// One point at a time
+			if err := writer.AddNumeric(id, &dto.NumericDatapoint{Timestamp: int64(i), Value: float64(i)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	}
+	stringPoints := []*dto.StringDatapoint{{Timestamp: 1, Value: "a"}, {Timestamp: 2, Value: "b"}}
+	if err := writer.AddString(dto.Identity{Id: 42}, stringPoints...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report.Written != 23 || report.Failed != 0 {
+		t.Errorf("Expected 23 written and 0 failed, got %d and %d", report.Written, report.Failed)
+	}
+	if report.Series["externalId=ts-1"].Written != 7 || report.Series["id=42"].Written != 2 {
+		t.Errorf("Unexpected per-series report %+v", report.Series)
+	}
+
+	total := 0
+	for _, request := range recorder.requests {
+		points := countDatapoints(request)
+		if points > 10 {
+			t.Errorf("Request with %d datapoints exceeds the limit", points)
+		}
+		if len(request.GetItems()) > 2 {
+			t.Errorf("Request with %d items exceeds the limit", len(request.GetItems()))
+		}
+		total += points
+	}
+	if total != 23 {
+		t.Errorf("Expected 23 datapoints sent, got %d", total)
+	}
+}
+
+func TestDatapointWriter_stringBytesLimit(t *testing.T) {
+	recorder := &insertRecorder{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		recorder.decode(t, r)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	writer := NewDatapointWriter(&client.TimeSeries, DatapointWriterConfig{MaxStringBytesPerRequest: 1000})
+	value := strings.Repeat("x", 400)
+	for i := 0; i < 5; i++ {
+		_ = writer.AddString(dto.Identity{ExternalId: "log"}, &dto.StringDatapoint{Timestamp: int64(i), Value: value})
+	}
+	if err := writer.AddString(dto.Identity{ExternalId: "log"}, &dto.StringDatapoint{Value: strings.Repeat("x", 2000)}); err == nil {
+		t.Error("Expected error for oversized string value")
+	}
+
+	if _, err := writer.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recorder.requests) != 3 {
+		t.Errorf("Expected 3 requests, got %d", len(recorder.requests))
+	}
+}
+
+func TestDatapointWriter_retriesAndReportsFailures(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		insertion := (&insertRecorder{}).decode(t, r)
+		externalId := insertion.GetItems()[0].GetExternalId()
+
+		mu.Lock()
+		attempts[externalId]++
+		attempt := attempts[externalId]
+		mu.Unlock()
+
+		switch {
+		case externalId == "flaky" && attempt == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case externalId == "invalid":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Time series not found","missing":[{"externalId":"invalid"}]}}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+
+	writer := NewDatapointWriter(&client.TimeSeries, DatapointWriterConfig{
+		MaxItemsPerRequest: 1,
+		RetryBackoff:       time.Millisecond,
+	})
+	_ = writer.AddNumeric(dto.Identity{ExternalId: "flaky"}, &dto.NumericDatapoint{Timestamp: 1})
+	_ = writer.AddNumeric(dto.Identity{ExternalId: "invalid"}, &dto.NumericDatapoint{Timestamp: 1}, &dto.NumericDatapoint{Timestamp: 2})
+
+	report, err := writer.Close()
+	if err == nil {
+		t.Error("Expected error for failed datapoints")
+	}
+	if attempts["flaky"] != 2 {
+		t.Errorf("Expected flaky batch to be retried once, got %d attempts", attempts["flaky"])
+	}
+	if attempts["invalid"] != 1 {
+		t.Errorf("Expected client errors not to be retried, got %d attempts", attempts["invalid"])
+	}
+	if report.Series["externalId=flaky"].Written != 1 {
+		t.Errorf("Expected flaky series to be written, got %+v", report.Series["externalId=flaky"])
+	}
+	invalid := report.Series["externalId=invalid"]
+	if invalid.Failed != 2 || invalid.LastError == nil {
+		t.Errorf("Expected invalid series to fail, got %+v", invalid)
+	}
+}
+
+func TestDatapointWriter_flushInterval(t *testing.T) {
+	received := make(chan struct{}, 1)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+		received <- struct{}{}
+	})
+
+	writer := NewDatapointWriter(&client.TimeSeries, DatapointWriterConfig{FlushInterval: 10 * time.Millisecond})
+	_ = writer.AddNumeric(dto.Identity{Id: 1}, &dto.NumericDatapoint{Timestamp: 1})
+
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected buffered datapoints to be flushed on interval")
+	}
+
+	if _, err := writer.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := writer.AddNumeric(dto.Identity{Id: 1}); err == nil {
+		t.Error("Expected error when adding to a closed writer")
+	}
+}
+
+func TestDatapointWriter_flushWhileAdding(t *testing.T) {
+	recorder := &insertRecorder{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		recorder.decode(t, r)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	writer := NewDatapointWriter(&client.TimeSeries, DatapointWriterConfig{FlushSize: 10, MaxConcurrentRequests: 2})
+
+	var adders sync.WaitGroup
+	for series := int64(1); series <= 4; series++ {
+		adders.Add(1)
+		go func() {
+			defer adders.Done()
+			for ts := int64(0); ts < 100; ts++ {
+				_ = writer.AddNumeric(dto.Identity{Id: series}, &dto.NumericDatapoint{Timestamp: ts})
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		writer.Flush()
+	}
+	adders.Wait()
+
+	report, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Written != 400 {
+		t.Errorf("Expected 400 written datapoints, got %d", report.Written)
+	}
+	total := 0
+	for _, request := range recorder.requests {
+		total += countDatapoints(request)
+	}
+	if total != 400 {
+		t.Errorf("Expected 400 datapoints sent, got %d", total)
+	}
+}
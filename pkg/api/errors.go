@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// APIError is returned when CDF responds with a non-OK status code. Missing
+// and Duplicated list the identifiers CDF reported as unknown or conflicting.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+	RequestId  string
+	Missing    []dto.Identity
+	Duplicated []dto.Identity
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Status)
+	if e.Message != "" {
+		sb.WriteString(" - ")
+		sb.WriteString(e.Message)
+	}
+	if len(e.Missing) > 0 {
+		fmt.Fprintf(&sb, " (missing: %s)", formatIdentities(e.Missing))
+	}
+	if len(e.Duplicated) > 0 {
+		fmt.Fprintf(&sb, " (duplicated: %s)", formatIdentities(e.Duplicated))
+	}
+	if e.RequestId != "" {
+		fmt.Fprintf(&sb, " [request id: %s]", e.RequestId)
+	}
+	return sb.String()
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestId:  resp.Header.Get("X-Request-Id"),
+	}
+
+	var errorResponse dto.ErrorResponse
+	if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Message != "" {
+		apiErr.Message = errorResponse.Error.Message
+		apiErr.Missing = errorResponse.Error.Missing
+		apiErr.Duplicated = errorResponse.Error.Duplicated
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+func formatIdentities(ids []dto.Identity) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, formatIdentity(id))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatIdentity(id dto.Identity) string {
+	switch {
+	case id.InstanceId != nil:
+		return fmt.Sprintf("instanceId=%s:%s", id.InstanceId.Space, id.InstanceId.ExternalId)
+	case id.ExternalId != "":
+		return fmt.Sprintf("externalId=%s", id.ExternalId)
+	default:
+		return fmt.Sprintf("id=%d", id.Id)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		expectedMessage string
+		expectedMissing int
+		expectedDup     int
+	}{
+		{
+			name:            "CDF error body with missing items",
+			body:            `{"error":{"code":400,"message":"Ids not found","missing":[{"externalId":"a"},{"id":2}]}}`,
+			expectedMessage: "Ids not found",
+			expectedMissing: 2,
+		},
+		{
+			name:            "CDF error body with duplicated items",
+			body:            `{"error":{"code":409,"message":"Duplicates","duplicated":[{"instanceId":{"space":"s","externalId":"x"}}]}}`,
+			expectedMessage: "Duplicates",
+			expectedDup:     1,
+		},
+		{
+			name:            "Plain text body",
+			body:            "upstream unavailable\n",
+			expectedMessage: "upstream unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Status:     "400 Bad Request",
+				Header:     http.Header{"X-Request-Id": []string{"req-1"}},
+			}
+			apiErr := newAPIError(resp, []byte(tt.body))
+
+			if apiErr.Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, apiErr.Message)
+			}
+			if len(apiErr.Missing) != tt.expectedMissing {
+				t.Errorf("Expected %d missing, got %d", tt.expectedMissing, len(apiErr.Missing))
+			}
+			if len(apiErr.Duplicated) != tt.expectedDup {
+				t.Errorf("Expected %d duplicated, got %d", tt.expectedDup, len(apiErr.Duplicated))
+			}
+			if apiErr.RequestId != "req-1" {
+				t.Errorf("Expected request id req-1, got %s", apiErr.RequestId)
+			}
+		})
+	}
+}
+
+func TestAPIError_Error(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusBadRequest, Status: "400 Bad Request", Header: http.Header{}}
+	body := `{"error":{"code":400,"message":"Ids not found","missing":[{"externalId":"a"},{"id":2}]}}`
+
+	var err error = newAPIError(resp, []byte(body))
+	expected := "400 Bad Request - Ids not found (missing: externalId=a, id=2)"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(apiErr.Error(), "missing") {
+		t.Error("Expected error to unwrap to *APIError")
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// spacesItemsLimit is the maximum number of spaces per apply, byids or
+// delete request.
+const spacesItemsLimit = 100
+
+// List lists one page of spaces. Pass the NextCursor of the previous page as
+// cursor to get the next one.
+func (s *Spaces) List(limit int, cursor *string, includeGlobal bool) (dto.SpaceList, error) {
+	queryParams := map[string]interface{}{
+		"limit":         limit,
+		"includeGlobal": includeGlobal,
+	}
+	if cursor != nil {
+		queryParams["cursor"] = url.QueryEscape(*cursor)
+	}
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/spaces?%s", s.Client.ClientConfig.Project, buildQueryParams(queryParams))
+
+	var spaceList dto.SpaceList
+	if err := doJSONRequest(s.Client, "GET", endpoint, nil, &spaceList); err != nil {
+		return dto.SpaceList{}, fmt.Errorf("failed to list spaces: %w", err)
+	}
+
+	return spaceList, nil
+}
+
+// ListAll lists every space, following the cursors.
+func (s *Spaces) ListAll(includeGlobal bool) (dto.SpaceList, error) {
+	var all dto.SpaceList
+	var cursor *string
+	for {
+		page, err := s.List(1000, cursor, includeGlobal)
+		if err != nil {
+			return all, err
+		}
+		all.Items = append(all.Items, page.Items...)
+		if page.NextCursor == nil || *page.NextCursor == "" {
+			return all, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// Retrieve fetches spaces by name. Unknown spaces are left out of the
+// result.
+func (s *Spaces) Retrieve(spaces []string) (dto.SpaceList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/spaces/byids", s.Client.ClientConfig.Project)
+
+	var retrieved dto.SpaceList
+	for start := 0; start < len(spaces); start += spacesItemsLimit {
+		end := min(start+spacesItemsLimit, len(spaces))
+
+		var spaceList dto.SpaceList
+		body := map[string]interface{}{"items": spaceIds(spaces[start:end])}
+		if err := doJSONRequest(s.Client, "POST", endpoint, body, &spaceList); err != nil {
+			return retrieved, fmt.Errorf("failed to retrieve spaces: %w", err)
+		}
+		retrieved.Items = append(retrieved.Items, spaceList.Items...)
+	}
+
+	return retrieved, nil
+}
+
+// Apply creates the spaces that do not exist and updates the others.
+func (s *Spaces) Apply(items []dto.SpaceApply) (dto.SpaceList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/spaces", s.Client.ClientConfig.Project)
+
+	var applied dto.SpaceList
+	for start := 0; start < len(items); start += spacesItemsLimit {
+		end := min(start+spacesItemsLimit, len(items))
+
+		var spaceList dto.SpaceList
+		body := map[string]interface{}{"items": items[start:end]}
+		if err := doJSONRequest(s.Client, "POST", endpoint, body, &spaceList); err != nil {
+			return applied, fmt.Errorf("failed to apply spaces: %w", err)
+		}
+		applied.Items = append(applied.Items, spaceList.Items...)
+	}
+
+	return applied, nil
+}
+
+// Delete deletes spaces by name and returns the names CDF deleted. A space
+// must be empty before it can be deleted.
+func (s *Spaces) Delete(spaces []string) ([]string, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/spaces/delete", s.Client.ClientConfig.Project)
+
+	var deleted []string
+	for start := 0; start < len(spaces); start += spacesItemsLimit {
+		end := min(start+spacesItemsLimit, len(spaces))
+
+		var response struct {
+			Items []dto.SpaceId `json:"items"`
+		}
+		body := map[string]interface{}{"items": spaceIds(spaces[start:end])}
+		if err := doJSONRequest(s.Client, "POST", endpoint, body, &response); err != nil {
+			return deleted, fmt.Errorf("failed to delete spaces: %w", err)
+		}
+		for _, item := range response.Items {
+			deleted = append(deleted, item.Space)
+		}
+	}
+
+	return deleted, nil
+}
+
+func spaceIds(spaces []string) []dto.SpaceId {
+	ids := make([]dto.SpaceId, len(spaces))
+	for i, space := range spaces {
+		ids[i] = dto.SpaceId{Space: space}
+	}
+	return ids
+}
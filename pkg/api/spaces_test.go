@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestSpaces_struct(t *testing.T) {
+	client := NewCogniteClient(ClientConfig{
+		ClientName:  "test-client",
+		Cluster:     "test-cluster",
+		Project:     "test-project",
+		Credentials: &mockCredentialProvider{token: "test-token"},
+	})
+
+	if client.DataModeling.Spaces.Client == nil {
+		t.Error("Expected DataModeling.Spaces.Client to be non-nil")
+	}
+}
+
+func TestSpaces_ListAll(t *testing.T) {
+	var queries []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/v1/projects/test-project/models/spaces" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		queries = append(queries, r.URL.RawQuery)
+
+		if r.URL.Query().Get("cursor") == "" {
+			cursor := "next/page=="
+			_ = json.NewEncoder(w).Encode(dto.SpaceList{Items: []dto.Space{{Space: "site-a"}}, NextCursor: &cursor})
+			return
+		}
+		if r.URL.Query().Get("cursor") != "next/page==" {
+			t.Errorf("Expected the cursor to round-trip, got %q", r.URL.Query().Get("cursor"))
+		}
+		_ = json.NewEncoder(w).Encode(dto.SpaceList{Items: []dto.Space{{Space: "cdf_cdm", IsGlobal: true}}})
+	})
+
+	spaces, err := client.DataModeling.Spaces.ListAll(true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(spaces.Items) != 2 || spaces.Items[1].Space != "cdf_cdm" || !spaces.Items[1].IsGlobal {
+		t.Errorf("Unexpected spaces: %+v", spaces.Items)
+	}
+	if len(queries) != 2 || !strings.Contains(queries[0], "includeGlobal=true") || !strings.Contains(queries[0], "limit=1000") {
+		t.Errorf("Unexpected queries: %v", queries)
+	}
+}
+
+func TestSpaces_Apply(t *testing.T) {
+	var batches []int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/projects/test-project/models/spaces" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Items []dto.SpaceApply `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+		batches = append(batches, len(body.Items))
+
+		var response dto.SpaceList
+		for _, item := range body.Items {
+			response.Items = append(response.Items, dto.Space{Space: item.Space, Name: item.Name})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	})
+
+	var items []dto.SpaceApply
+	for i := 0; i < 150; i++ {
+		items = append(items, dto.SpaceApply{Space: "site-" + string(rune('a'+i%26)), Name: "Site"})
+	}
+
+	applied, err := client.DataModeling.Spaces.Apply(items)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(batches) != 2 || batches[0] != 100 || batches[1] != 50 {
+		t.Errorf("Expected batches of 100 and 50, got %v", batches)
+	}
+	if len(applied.Items) != 150 {
+		t.Errorf("Expected 150 applied spaces, got %d", len(applied.Items))
+	}
+}
+
+func TestSpaces_RetrieveAndDelete(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"items":[{"space":"site-a"},{"space":"site-b"}]}` {
+			t.Errorf("Unexpected body %s", body)
+		}
+		switch r.URL.Path {
+		case "/api/v1/projects/test-project/models/spaces/byids":
+			_ = json.NewEncoder(w).Encode(dto.SpaceList{Items: []dto.Space{{Space: "site-a"}}})
+		case "/api/v1/projects/test-project/models/spaces/delete":
+			_, _ = w.Write([]byte(`{"items":[{"space":"site-a"},{"space":"site-b"}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	spaces, err := client.DataModeling.Spaces.Retrieve([]string{"site-a", "site-b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(spaces.Items) != 1 || spaces.Items[0].Space != "site-a" {
+		t.Errorf("Unexpected spaces: %+v", spaces.Items)
+	}
+
+	deleted, err := client.DataModeling.Spaces.Delete([]string{"site-a", "site-b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleted) != 2 || deleted[1] != "site-b" {
+		t.Errorf("Expected both spaces deleted, got %v", deleted)
+	}
+}
+
+func TestSpaces_errors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Space must be empty"}}`))
+	})
+
+	_, err := client.DataModeling.Spaces.Delete([]string{"site-a"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Space must be empty" {
+		t.Errorf("Expected an APIError, got %v", err)
+	}
+}
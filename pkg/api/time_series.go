@@ -129,6 +129,10 @@ func (t *TimeSeries) Filter(
 	return tsList, nil
 }
 
+// RetrieveData fetches datapoints for the given items. Items are split into
+// as many concurrent requests as CDF's per-request limits require, and the
+// results are returned in input order. Use RetrieveDataBatched to also learn
+// which items were unknown when ignoreUnknownIds is set.
 func (t *TimeSeries) RetrieveData(
 	items *[]dto.DataPointsQueryItem,
 	startTime *string,
@@ -139,6 +143,32 @@ func (t *TimeSeries) RetrieveData(
 	includeOutsidePoints *bool,
 	timeZone *string,
 	ignoreUnknownIds *bool,
+) (*dto.DataPointListResponse, error) {
+	response, _, err := t.RetrieveDataBatched(
+		items, startTime, endTime, limit, aggregates, granularity,
+		includeOutsidePoints, timeZone, ignoreUnknownIds, defaultRetrieveConcurrency,
+	)
+	return response, err
+}
+
+// dataPointsDefaults holds the top-level parameters of a datapoints request.
+// CDF applies them to every item that does not set its own value.
+type dataPointsDefaults struct {
+	Start                *string
+	End                  *string
+	Limit                *int64
+	Aggregates           *[]string
+	Granularity          *string
+	IncludeOutsidePoints *bool
+	TimeZone             *string
+	IgnoreUnknownIds     *bool
+	IgnoreBadDataPoints  *bool
+}
+
+// retrieveDataPage sends a single datapoints request.
+func (t *TimeSeries) retrieveDataPage(
+	items *[]dto.DataPointsQueryItem,
+	defaults dataPointsDefaults,
 ) (*dto.DataPointListResponse, error) {
 	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/data/list", t.Client.ClientConfig.Project)
 	url := t.Client.BaseURL + endpoint
@@ -147,29 +177,32 @@ func (t *TimeSeries) RetrieveData(
 	body["items"] = items
 
 	// Only add to body if parameters are not nil
-	if startTime != nil {
-		body["start"] = startTime
+	if defaults.Start != nil {
+		body["start"] = defaults.Start
 	}
-	if endTime != nil {
-		body["end"] = endTime
+	if defaults.End != nil {
+		body["end"] = defaults.End
 	}
-	if limit != nil {
-		body["limit"] = limit
+	if defaults.Limit != nil {
+		body["limit"] = defaults.Limit
 	}
-	if aggregates != nil {
-		body["aggregates"] = aggregates
+	if defaults.Aggregates != nil {
+		body["aggregates"] = defaults.Aggregates
 	}
-	if granularity != nil {
-		body["granularity"] = granularity
+	if defaults.Granularity != nil {
+		body["granularity"] = defaults.Granularity
 	}
-	if includeOutsidePoints != nil {
-		body["includeOutsidePoints"] = includeOutsidePoints
+	if defaults.IncludeOutsidePoints != nil {
+		body["includeOutsidePoints"] = defaults.IncludeOutsidePoints
 	}
-	if timeZone != nil {
-		body["timeZone"] = timeZone
+	if defaults.TimeZone != nil {
+		body["timeZone"] = defaults.TimeZone
 	}
-	if ignoreUnknownIds != nil {
-		body["ignoreUnknownIds"] = ignoreUnknownIds
+	if defaults.IgnoreUnknownIds != nil {
+		body["ignoreUnknownIds"] = defaults.IgnoreUnknownIds
+	}
+	if defaults.IgnoreBadDataPoints != nil {
+		body["ignoreBadDataPoints"] = defaults.IgnoreBadDataPoints
 	}
 
 	// Convert the body to JSON
@@ -209,7 +242,11 @@ func (t *TimeSeries) RetrieveData(
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch datapoints: %s", resp.Status)
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch datapoints: %s - error reading response body: %v", resp.Status, err)
+		}
+		return nil, fmt.Errorf("failed to fetch datapoints: %w", newAPIError(resp, respBody))
 	}
 
 	// Read and decode the protobuf response
@@ -305,3 +342,232 @@ func (t *TimeSeries) RetrieveLatest(
 
 	return &dpList, nil
 }
+
+// timeSeriesItemsLimit is the maximum number of items CDF accepts in a single
+// create, retrieve, update or delete request.
+const timeSeriesItemsLimit = 1000
+
+// timeSeriesWrite is the subset of dto.TimeSeries accepted by the create endpoint.
+type timeSeriesWrite struct {
+	ExternalId         string       `json:"externalId,omitempty"`
+	Name               string       `json:"name,omitempty"`
+	IsString           bool         `json:"isString"`
+	Metadata           dto.Metadata `json:"metadata,omitempty"`
+	Unit               string       `json:"unit,omitempty"`
+	UnitExternalId     string       `json:"unitExternalId,omitempty"`
+	AssetId            int64        `json:"assetId,omitempty"`
+	IsStep             bool         `json:"isStep"`
+	Description        string       `json:"description,omitempty"`
+	SecurityCategories []int64      `json:"securityCategories,omitempty"`
+	DataSetID          int64        `json:"dataSetId,omitempty"`
+}
+
+func newTimeSeriesWrite(ts *dto.TimeSeries) timeSeriesWrite {
+	return timeSeriesWrite{
+		ExternalId:         ts.ExternalId,
+		Name:               ts.Name,
+		IsString:           ts.IsString,
+		Metadata:           ts.Metadata,
+		Unit:               ts.Unit,
+		UnitExternalId:     ts.UnitExternalId,
+		AssetId:            ts.AssetId,
+		IsStep:             ts.IsStep,
+		Description:        ts.Description,
+		SecurityCategories: ts.SecurityCategories,
+		DataSetID:          ts.DataSetID,
+	}
+}
+
+// Create creates the given time series, splitting them into requests of at
+// most 1000 items. Read-only fields such as Id and CreatedTime are ignored.
+// If a batch fails, the time series created by earlier batches are returned
+// together with the error.
+func (t *TimeSeries) Create(items []dto.TimeSeries) (dto.TimeSeriesList, error) {
+	writes := make([]timeSeriesWrite, 0, len(items))
+	for i := range items {
+		writes = append(writes, newTimeSeriesWrite(&items[i]))
+	}
+	return t.createWrites(writes)
+}
+
+func (t *TimeSeries) createWrites(items []timeSeriesWrite) (dto.TimeSeriesList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries", t.Client.ClientConfig.Project)
+
+	var created dto.TimeSeriesList
+	for start := 0; start < len(items); start += timeSeriesItemsLimit {
+		end := min(start+timeSeriesItemsLimit, len(items))
+
+		var tsList dto.TimeSeriesList
+		body := map[string]interface{}{"items": items[start:end]}
+		if err := doJSONRequest(t.Client, "POST", endpoint, body, &tsList); err != nil {
+			return created, fmt.Errorf("failed to create timeseries: %w", err)
+		}
+		created.Items = append(created.Items, tsList.Items...)
+	}
+
+	return created, nil
+}
+
+// Retrieve fetches time series by id, externalId or instanceId. When
+// ignoreUnknownIds is false, unknown identifiers are reported through the
+// Missing field of the returned *APIError.
+func (t *TimeSeries) Retrieve(ids []dto.Identity, ignoreUnknownIds bool) (dto.TimeSeriesList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/byids", t.Client.ClientConfig.Project)
+
+	var retrieved dto.TimeSeriesList
+	for start := 0; start < len(ids); start += timeSeriesItemsLimit {
+		end := min(start+timeSeriesItemsLimit, len(ids))
+
+		var tsList dto.TimeSeriesList
+		body := map[string]interface{}{
+			"items":            ids[start:end],
+			"ignoreUnknownIds": ignoreUnknownIds,
+		}
+		if err := doJSONRequest(t.Client, "POST", endpoint, body, &tsList); err != nil {
+			return retrieved, fmt.Errorf("failed to retrieve timeseries: %w", err)
+		}
+		retrieved.Items = append(retrieved.Items, tsList.Items...)
+	}
+
+	return retrieved, nil
+}
+
+// Update applies the given patches. Each item must set exactly one of Id,
+// ExternalId or InstanceId to address the time series it updates.
+func (t *TimeSeries) Update(items []dto.TimeSeriesUpdate) (dto.TimeSeriesList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/update", t.Client.ClientConfig.Project)
+
+	var updated dto.TimeSeriesList
+	for start := 0; start < len(items); start += timeSeriesItemsLimit {
+		end := min(start+timeSeriesItemsLimit, len(items))
+
+		var tsList dto.TimeSeriesList
+		body := map[string]interface{}{"items": items[start:end]}
+		if err := doJSONRequest(t.Client, "POST", endpoint, body, &tsList); err != nil {
+			return updated, fmt.Errorf("failed to update timeseries: %w", err)
+		}
+		updated.Items = append(updated.Items, tsList.Items...)
+	}
+
+	return updated, nil
+}
+
+// Delete deletes time series by id, externalId or instanceId.
+func (t *TimeSeries) Delete(ids []dto.Identity, ignoreUnknownIds bool) error {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/delete", t.Client.ClientConfig.Project)
+
+	for start := 0; start < len(ids); start += timeSeriesItemsLimit {
+		end := min(start+timeSeriesItemsLimit, len(ids))
+
+		body := map[string]interface{}{
+			"items":            ids[start:end],
+			"ignoreUnknownIds": ignoreUnknownIds,
+		}
+		if err := doJSONRequest(t.Client, "POST", endpoint, body, nil); err != nil {
+			return fmt.Errorf("failed to delete timeseries: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Search performs a fuzzy free-text search on name, description or both
+// (query), optionally restricted by filter.
+func (t *TimeSeries) Search(
+	filter *dto.TimeSeriesFilter,
+	search dto.TimeSeriesSearch,
+	limit int,
+) (dto.TimeSeriesList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/search", t.Client.ClientConfig.Project)
+
+	body := map[string]interface{}{
+		"search": search,
+		"limit":  limit,
+	}
+	if filter != nil {
+		body["filter"] = filter
+	}
+
+	var tsList dto.TimeSeriesList
+	if err := doJSONRequest(t.Client, "POST", endpoint, body, &tsList); err != nil {
+		return dto.TimeSeriesList{}, fmt.Errorf("failed to search timeseries: %w", err)
+	}
+
+	return tsList, nil
+}
+
+// Aggregate runs a count, uniqueValues, uniqueProperties or cardinality
+// aggregate over time series properties and metadata keys. Leave
+// request.Aggregate empty to count the matching time series.
+func (t *TimeSeries) Aggregate(request dto.TimeSeriesAggregateRequest) (dto.TimeSeriesAggregateList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/aggregate", t.Client.ClientConfig.Project)
+
+	var aggregateList dto.TimeSeriesAggregateList
+	if err := doJSONRequest(t.Client, "POST", endpoint, request, &aggregateList); err != nil {
+		return dto.TimeSeriesAggregateList{}, fmt.Errorf("failed to aggregate timeseries: %w", err)
+	}
+
+	return aggregateList, nil
+}
+
+// InsertData inserts numeric and string datapoints using the protobuf
+// insertion format. Items can be built with dto.NewNumericInsertionItem and
+// dto.NewStringInsertionItem; null values are sent by setting NullValue on a
+// datapoint. The request is sent as is, so it must respect CDF's per-request
+// limits.
+func (t *TimeSeries) InsertData(items []*dto.DataPointInsertionItem) error {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/data", t.Client.ClientConfig.Project)
+	url := t.Client.BaseURL + endpoint
+
+	for i, item := range items {
+		if item.GetTimeSeriesReference() == nil {
+			return fmt.Errorf("datapoint insertion item %d has no id, externalId or instanceId", i)
+		}
+		if item.GetDatapointType() == nil {
+			return fmt.Errorf("datapoint insertion item %d has no datapoints", i)
+		}
+	}
+
+	bodyProto, err := proto.Marshal(&dto.DataPointInsertionRequest{Items: items})
+	if err != nil {
+		return err
+	}
+
+	// Compress the protobuf body
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(bodyProto); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, &buf)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range t.Client.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	httpClient := &http.Client{}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to insert datapoints: %w", newAPIError(resp, responseBody))
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+const (
+	// syntheticItemsLimit is the maximum number of expressions per request.
+	syntheticItemsLimit = 10
+	// syntheticDatapointsLimit is the maximum number of datapoints CDF
+	// returns for a single expression per request.
+	syntheticDatapointsLimit = 10000
+)
+
+type syntheticQueryRequestItem struct {
+	Expression string      `json:"expression"`
+	Start      interface{} `json:"start,omitempty"`
+	End        interface{} `json:"end,omitempty"`
+	Limit      int         `json:"limit"`
+}
+
+// syntheticQueryState tracks the paging progress of a single expression.
+type syntheticQueryState struct {
+	index int
+	item  syntheticQueryRequestItem
+	limit int
+}
+
+// SyntheticQuery evaluates synthetic time series expressions such as
+// "ts{externalId='a'} * 1.8 + 32" on the server. Each item is paged
+// independently until its range is covered or its Limit is reached; a zero
+// Limit fetches the whole range. Results are returned in input order, and
+// datapoints that failed to evaluate carry the error in their Error field.
+func (t *TimeSeries) SyntheticQuery(items []dto.SyntheticQueryItem) (dto.SyntheticQueryResponse, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/timeseries/synthetic/query", t.Client.ClientConfig.Project)
+
+	results := make([]dto.SyntheticQueryResult, len(items))
+	pending := make([]*syntheticQueryState, 0, len(items))
+	for i, item := range items {
+		state := &syntheticQueryState{
+			index: i,
+			item:  syntheticQueryRequestItem{Expression: item.Expression},
+			limit: item.Limit,
+		}
+		if item.Start != "" {
+			state.item.Start = item.Start
+		}
+		if item.End != "" {
+			state.item.End = item.End
+		}
+		pending = append(pending, state)
+	}
+
+	for len(pending) > 0 {
+		batch := pending[:min(syntheticItemsLimit, len(pending))]
+		requestItems := make([]syntheticQueryRequestItem, 0, len(batch))
+		for _, state := range batch {
+			state.item.Limit = syntheticDatapointsLimit
+			if state.limit > 0 {
+				state.item.Limit = min(syntheticDatapointsLimit, state.limit-len(results[state.index].Datapoints))
+			}
+			requestItems = append(requestItems, state.item)
+		}
+
+		var response dto.SyntheticQueryResponse
+		body := map[string]interface{}{"items": requestItems}
+		if err := doJSONRequest(t.Client, "POST", endpoint, body, &response); err != nil {
+			return dto.SyntheticQueryResponse{}, fmt.Errorf("failed to query synthetic timeseries: %w", err)
+		}
+		if len(response.Items) != len(batch) {
+			return dto.SyntheticQueryResponse{}, fmt.Errorf("failed to query synthetic timeseries: expected %d results, got %d", len(batch), len(response.Items))
+		}
+
+		var next []*syntheticQueryState
+		for i, state := range batch {
+			page := response.Items[i]
+			result := &results[state.index]
+			result.IsString = page.IsString
+			result.Datapoints = append(result.Datapoints, page.Datapoints...)
+
+			reachedLimit := state.limit > 0 && len(result.Datapoints) >= state.limit
+			if len(page.Datapoints) < state.item.Limit || reachedLimit {
+				continue
+			}
+			state.item.Start = page.Datapoints[len(page.Datapoints)-1].Timestamp + 1
+			next = append(next, state)
+		}
+		pending = append(next, pending[len(batch):]...)
+	}
+
+	return dto.SyntheticQueryResponse{Items: results}, nil
+}
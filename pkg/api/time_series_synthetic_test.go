@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestTimeSeries_SyntheticQuery_paging(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var body struct {
+			Items []syntheticQueryRequestItem `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+
+		var out []string
+		for _, item := range body.Items {
+			// Serve 25000 points for the first expression and 2 for the second
+			total := int64(25000)
+			if strings.Contains(item.Expression, "b") {
+				total = 2
+			}
+			start := int64(0)
+			if s, ok := item.Start.(float64); ok {
+				start = int64(s)
+			}
+			var points []string
+			for ts := start; ts < total && len(points) < item.Limit; ts++ {
+				if ts == 1 {
+					points = append(points, fmt.Sprintf(`{"timestamp":%d,"error":"division by zero"}`, ts))
+					continue
+				}
+				points = append(points, fmt.Sprintf(`{"timestamp":%d,"value":%d}`, ts, ts))
+			}
+			out = append(out, fmt.Sprintf(`{"isString":false,"datapoints":[%s]}`, strings.Join(points, ",")))
+		}
+		fmt.Fprintf(w, `{"items":[%s]}`, strings.Join(out, ","))
+	})
+
+	response, err := client.TimeSeries.SyntheticQuery([]dto.SyntheticQueryItem{
+		{Expression: "ts{externalId='a'} * 1.8 + 32", Start: "2d-ago", End: "now"},
+		{Expression: "ts{externalId='b'}", Start: "2d-ago", End: "now"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+	if len(response.Items[0].Datapoints) != 25000 {
+		t.Errorf("Expected 25000 datapoints, got %d", len(response.Items[0].Datapoints))
+	}
+	if len(response.Items[1].Datapoints) != 2 {
+		t.Errorf("Expected 2 datapoints, got %d", len(response.Items[1].Datapoints))
+	}
+	if response.Items[1].Datapoints[1].Error != "division by zero" {
+		t.Errorf("Expected datapoint error to be decoded, got %+v", response.Items[1].Datapoints[1])
+	}
+	last := response.Items[0].Datapoints[24999]
+	if last.Timestamp != 24999 || last.Value != 24999 {
+		t.Errorf("Unexpected last datapoint %+v", last)
+	}
+}
+
+func TestTimeSeries_SyntheticQuery_limit(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Items []syntheticQueryRequestItem `json:"items"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Items[0].Limit != 5 {
+			t.Errorf("Expected limit 5, got %d", body.Items[0].Limit)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"datapoints":[{"timestamp":1,"value":1},{"timestamp":2,"value":2}]}]}`))
+	})
+
+	response, err := client.TimeSeries.SyntheticQuery([]dto.SyntheticQueryItem{
+		{Expression: "ts{id=1}", Limit: 5},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(response.Items[0].Datapoints) != 2 {
+		t.Errorf("Expected 2 datapoints, got %d", len(response.Items[0].Datapoints))
+	}
+}
+
+func TestTimeSeries_SyntheticQuery_values(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"items":[` +
+			`{"isString":true,"datapoints":[{"timestamp":1,"value":"OPEN"},{"timestamp":2,"value":"CLOSED"},{"timestamp":3,"value":""}]},` +
+			`{"isString":false,"datapoints":[{"timestamp":1,"value":2.5},{"timestamp":2,"error":"division by zero"}]}]}`))
+	})
+
+	response, err := client.TimeSeries.SyntheticQuery([]dto.SyntheticQueryItem{
+		{Expression: "ts{externalId='valve'}", Limit: 2},
+		{Expression: "ts{externalId='a'} / ts{externalId='b'}", Limit: 2},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	closed, empty := "CLOSED", ""
+	tests := []struct {
+		name     string
+		got      dto.SyntheticDatapoint
+		expected dto.SyntheticDatapoint
+	}{
+		{"String value", response.Items[0].Datapoints[1], dto.SyntheticDatapoint{Timestamp: 2, StringValue: &closed}},
+		{"Empty string value", response.Items[0].Datapoints[2], dto.SyntheticDatapoint{Timestamp: 3, StringValue: &empty}},
+		{"Numeric value", response.Items[1].Datapoints[0], dto.SyntheticDatapoint{Timestamp: 1, Value: 2.5}},
+		{"Error", response.Items[1].Datapoints[1], dto.SyntheticDatapoint{Timestamp: 2, Error: "division by zero"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !equalSyntheticDatapoints(tt.got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, tt.got)
+			}
+
+			// A round trip keeps the kind of the value
+			data, err := json.Marshal(tt.got)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var decoded dto.SyntheticDatapoint
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !equalSyntheticDatapoints(decoded, tt.expected) {
+				t.Errorf("Expected %+v after a round trip through %s, got %+v", tt.expected, data, decoded)
+			}
+		})
+	}
+	if !response.Items[0].IsString {
+		t.Error("Expected the first result to be a string result")
+	}
+}
+
+func equalSyntheticDatapoints(a, b dto.SyntheticDatapoint) bool {
+	if (a.StringValue == nil) != (b.StringValue == nil) || (a.StringValue != nil && *a.StringValue != *b.StringValue) {
+		return false
+	}
+	return a.Timestamp == b.Timestamp && a.Value == b.Value && a.Error == b.Error
+}
@@ -1,7 +1,17 @@
 package api
 
 import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+
+	"google.golang.org/protobuf/proto"
 )
 
 func TestTimeSeries_struct(t *testing.T) {
@@ -23,3 +33,228 @@ func TestTimeSeries_struct(t *testing.T) {
 		t.Error("Expected TimeSeries.Client to be properly initialized")
 	}
 }
+
+func TestTimeSeries_Create_batches(t *testing.T) {
+	var batchSizes []int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/test-project/timeseries" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var body struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		if _, ok := body.Items[0]["createdTime"]; ok {
+			t.Error("Expected read-only fields to be omitted")
+		}
+		batchSizes = append(batchSizes, len(body.Items))
+
+		resp := dto.TimeSeriesList{}
+		for _, item := range body.Items {
+			resp.Items = append(resp.Items, dto.TimeSeries{ExternalId: item["externalId"].(string)})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	items := make([]dto.TimeSeries, 2500)
+	for i := range items {
+		items[i].ExternalId = fmt.Sprintf("ts-%d", i)
+		items[i].CreatedTime = 1
+	}
+
+	created, err := client.TimeSeries.Create(items)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(created.Items) != 2500 {
+		t.Errorf("Expected 2500 created items, got %d", len(created.Items))
+	}
+	if len(batchSizes) != 3 || batchSizes[0] != 1000 || batchSizes[2] != 500 {
+		t.Errorf("Unexpected batch sizes %v", batchSizes)
+	}
+}
+
+func TestTimeSeries_Retrieve_missing(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Ids not found","missing":[{"externalId":"nope"}]}}`))
+	})
+
+	_, err := client.TimeSeries.Retrieve([]dto.Identity{{ExternalId: "nope"}}, false)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %v", err)
+	}
+	if len(apiErr.Missing) != 1 || apiErr.Missing[0].ExternalId != "nope" {
+		t.Errorf("Unexpected missing identifiers %v", apiErr.Missing)
+	}
+}
+
+func TestTimeSeries_Update_body(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"items":[{"externalId":"a","update":{"metadata":{"add":{"k":"v"},"remove":["old"]},"securityCategories":{"remove":[7]}}}]}`
+		if string(body) != expected {
+			t.Errorf("Unexpected body\n got: %s\nwant: %s", body, expected)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"externalId":"a"}]}`))
+	})
+
+	updated, err := client.TimeSeries.Update([]dto.TimeSeriesUpdate{
+		{
+			ExternalId: "a",
+			Update: dto.TimeSeriesPatch{
+				Metadata:           &dto.MetadataPatch{Add: dto.Metadata{"k": "v"}, Remove: []string{"old"}},
+				SecurityCategories: &dto.Int64ListPatch{Remove: []int64{7}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(updated.Items) != 1 {
+		t.Errorf("Expected 1 updated item, got %d", len(updated.Items))
+	}
+}
+
+func TestTimeSeries_Search(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/test-project/timeseries/search" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"filter":{"unitQuantity":"Pressure"},"limit":10,"search":{"query":"pump pressure"}}`
+		if string(body) != expected {
+			t.Errorf("Unexpected body\n got: %s\nwant: %s", body, expected)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"id":1,"externalId":"pt-1"}]}`))
+	})
+
+	tsList, err := client.TimeSeries.Search(
+		&dto.TimeSeriesFilter{UnitQuantity: "Pressure"},
+		dto.TimeSeriesSearch{Query: "pump pressure"},
+		10,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tsList.Items) != 1 || tsList.Items[0].ExternalId != "pt-1" {
+		t.Errorf("Unexpected search result %+v", tsList.Items)
+	}
+}
+
+func TestTimeSeries_Aggregate(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"aggregate":"uniqueValues","properties":[{"property":["metadata","site"]}]}`
+		if string(body) != expected {
+			t.Errorf("Unexpected body\n got: %s\nwant: %s", body, expected)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"count":3,"values":["oslo"]},{"count":1,"values":["bergen"]}]}`))
+	})
+
+	aggregates, err := client.TimeSeries.Aggregate(dto.TimeSeriesAggregateRequest{
+		Aggregate:  dto.TimeSeriesAggregateUniqueValues,
+		Properties: []dto.TimeSeriesAggregateProperty{{Property: []string{"metadata", "site"}}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(aggregates.Items) != 2 || aggregates.Items[0].Count != 3 || aggregates.Items[0].Values[0] != "oslo" {
+		t.Errorf("Unexpected aggregate result %+v", aggregates.Items)
+	}
+}
+
+func TestTimeSeries_InsertData(t *testing.T) {
+	var received dto.DataPointInsertionRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/test-project/timeseries/data" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Content-Type") != "application/protobuf" || r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatalf("Failed to open gzip body: %v", err)
+		}
+		body, _ := io.ReadAll(gz)
+		if err := proto.Unmarshal(body, &received); err != nil {
+			t.Fatalf("Failed to decode protobuf body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	err := client.TimeSeries.InsertData([]*dto.DataPointInsertionItem{
+		dto.NewNumericInsertionItem(dto.Identity{ExternalId: "temp"}, []*dto.NumericDatapoint{
+			{Timestamp: 1000, Value: 21.5},
+			{Timestamp: 2000, NullValue: true, Status: &dto.Status{Code: 2147483648, Symbol: "Bad"}},
+		}),
+		dto.NewStringInsertionItem(dto.Identity{InstanceId: &dto.InstanceId{Space: "sp", ExternalId: "state"}}, []*dto.StringDatapoint{
+			{Timestamp: 1000, Value: "running"},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	items := received.GetItems()
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+	if items[0].GetExternalId() != "temp" || len(items[0].GetNumericDatapoints().GetDatapoints()) != 2 {
+		t.Errorf("Unexpected numeric item %v", items[0])
+	}
+	if !items[0].GetNumericDatapoints().GetDatapoints()[1].GetNullValue() {
+		t.Error("Expected null value to be preserved")
+	}
+	if items[1].GetInstanceId().GetSpace() != "sp" || items[1].GetStringDatapoints().GetDatapoints()[0].GetValue() != "running" {
+		t.Errorf("Unexpected string item %v", items[1])
+	}
+}
+
+func TestTimeSeries_InsertData_missingReference(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request to be sent")
+	})
+
+	err := client.TimeSeries.InsertData([]*dto.DataPointInsertionItem{
+		dto.NewNumericInsertionItem(dto.Identity{}, nil),
+	})
+	if err == nil {
+		t.Error("Expected error for item without reference")
+	}
+}
+
+func TestDataPointsQueryItem_json(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     dto.DataPointsQueryItem
+		expected string
+	}{
+		{"Relative times stay strings", dto.DataPointsQueryItem{Id: 1, Start: "2d-ago", End: "now"}, `{"id":1,"start":"2d-ago","end":"now"}`},
+		{"Epoch milliseconds are numbers", dto.DataPointsQueryItem{Id: 1, Start: "0", End: "1700000000000"}, `{"id":1,"start":0,"end":1700000000000}`},
+		{"Empty times are left out", dto.DataPointsQueryItem{Id: 1}, `{"id":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.item)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+			var decoded dto.DataPointsQueryItem
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if decoded.Start != tt.item.Start || decoded.End != tt.item.End {
+				t.Errorf("Expected %q to %q after a round trip, got %q to %q", tt.item.Start, tt.item.End, decoded.Start, decoded.End)
+			}
+		})
+	}
+}
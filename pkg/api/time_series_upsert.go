@@ -0,0 +1,218 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// TimeSeriesUpsertResult reports what Upsert did with each declared time series.
+type TimeSeriesUpsertResult struct {
+	Created   dto.TimeSeriesList
+	Updated   dto.TimeSeriesList
+	Unchanged int
+}
+
+// Upsert reconciles CDF with the declared time series. Items are matched on
+// ExternalId: missing ones are created and existing ones are patched with only
+// the fields that differ from the declaration. Empty fields in a declaration
+// clear the corresponding field in CDF. Time series another writer creates
+// between the lookup and the create are patched instead.
+func (t *TimeSeries) Upsert(items []dto.TimeSeries) (TimeSeriesUpsertResult, error) {
+	var result TimeSeriesUpsertResult
+
+	ids := make([]dto.Identity, 0, len(items))
+	desired := make(map[string]*dto.TimeSeries, len(items))
+	for i := range items {
+		externalId := items[i].ExternalId
+		if externalId == "" {
+			return result, fmt.Errorf("upsert requires an externalId on every time series (item %d)", i)
+		}
+		if desired[externalId] != nil {
+			return result, fmt.Errorf("duplicated externalId in upsert: %s", externalId)
+		}
+		desired[externalId] = &items[i]
+		ids = append(ids, dto.Identity{ExternalId: externalId})
+	}
+
+	existingList, err := t.Retrieve(ids, true)
+	if err != nil {
+		return result, err
+	}
+	existing := make(map[string]*dto.TimeSeries, len(existingList.Items))
+	for i := range existingList.Items {
+		existing[existingList.Items[i].ExternalId] = &existingList.Items[i]
+	}
+
+	var toCreate []timeSeriesWrite
+	var toUpdate []dto.TimeSeriesUpdate
+	reconcile := func(current, desired *dto.TimeSeries) error {
+		if current.IsString != desired.IsString {
+			return fmt.Errorf("cannot change isString of existing time series %s", current.ExternalId)
+		}
+		patch, changed := diffTimeSeries(current, desired)
+		if !changed {
+			result.Unchanged++
+			return nil
+		}
+		toUpdate = append(toUpdate, dto.TimeSeriesUpdate{ExternalId: current.ExternalId, Update: patch})
+		return nil
+	}
+	for i := range items {
+		current, ok := existing[items[i].ExternalId]
+		if !ok {
+			toCreate = append(toCreate, newTimeSeriesWrite(&items[i]))
+			continue
+		}
+		if err := reconcile(current, &items[i]); err != nil {
+			return result, err
+		}
+	}
+
+	for len(toCreate) > 0 {
+		created, createErr := t.createWrites(toCreate)
+		result.Created.Items = append(result.Created.Items, created.Items...)
+		if createErr == nil {
+			break
+		}
+		var apiErr *APIError
+		if !errors.As(createErr, &apiErr) || apiErr.StatusCode != http.StatusConflict || len(apiErr.Duplicated) == 0 {
+			return result, createErr
+		}
+
+		// Another writer created these since the lookup: patch them instead,
+		// and create the rest of the batches that were not sent
+		done := make(map[string]bool, len(created.Items)+len(apiErr.Duplicated))
+		for i := range created.Items {
+			done[created.Items[i].ExternalId] = true
+		}
+		conflicts, err := t.Retrieve(apiErr.Duplicated, true)
+		if err != nil {
+			return result, err
+		}
+		for i := range conflicts.Items {
+			current := &conflicts.Items[i]
+			if desired[current.ExternalId] == nil || done[current.ExternalId] {
+				continue
+			}
+			done[current.ExternalId] = true
+			if err := reconcile(current, desired[current.ExternalId]); err != nil {
+				return result, err
+			}
+		}
+		var remaining []timeSeriesWrite
+		for i := range toCreate {
+			if !done[toCreate[i].ExternalId] {
+				remaining = append(remaining, toCreate[i])
+			}
+		}
+		if len(remaining) == len(toCreate) {
+			return result, createErr
+		}
+		toCreate = remaining
+	}
+	if len(toUpdate) > 0 {
+		result.Updated, err = t.Update(toUpdate)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// diffTimeSeries computes the minimal patch that turns current into desired.
+// The second return value is false when the two are already equal.
+func diffTimeSeries(current, desired *dto.TimeSeries) (dto.TimeSeriesPatch, bool) {
+	var patch dto.TimeSeriesPatch
+	changed := false
+
+	stringPatch := func(currentValue, desiredValue string) *dto.StringPatch {
+		if currentValue == desiredValue {
+			return nil
+		}
+		changed = true
+		if desiredValue == "" {
+			return &dto.StringPatch{SetNull: true}
+		}
+		return &dto.StringPatch{Set: &desiredValue}
+	}
+	int64Patch := func(currentValue, desiredValue int64) *dto.Int64Patch {
+		if currentValue == desiredValue {
+			return nil
+		}
+		changed = true
+		if desiredValue == 0 {
+			return &dto.Int64Patch{SetNull: true}
+		}
+		return &dto.Int64Patch{Set: &desiredValue}
+	}
+
+	patch.Name = stringPatch(current.Name, desired.Name)
+	patch.Unit = stringPatch(current.Unit, desired.Unit)
+	patch.UnitExternalId = stringPatch(current.UnitExternalId, desired.UnitExternalId)
+	patch.Description = stringPatch(current.Description, desired.Description)
+	patch.AssetId = int64Patch(current.AssetId, desired.AssetId)
+	patch.DataSetID = int64Patch(current.DataSetID, desired.DataSetID)
+
+	if current.IsStep != desired.IsStep {
+		isStep := desired.IsStep
+		patch.IsStep = &dto.BoolPatch{Set: &isStep}
+		changed = true
+	}
+
+	if metadataPatch := diffMetadata(current.Metadata, desired.Metadata); metadataPatch != nil {
+		patch.Metadata = metadataPatch
+		changed = true
+	}
+
+	if categoriesPatch := diffInt64Set(current.SecurityCategories, desired.SecurityCategories); categoriesPatch != nil {
+		patch.SecurityCategories = categoriesPatch
+		changed = true
+	}
+
+	return patch, changed
+}
+
+func diffMetadata(current, desired dto.Metadata) *dto.MetadataPatch {
+	var patch dto.MetadataPatch
+	for key, value := range desired {
+		if currentValue, ok := current[key]; !ok || currentValue != value {
+			if patch.Add == nil {
+				patch.Add = dto.Metadata{}
+			}
+			patch.Add[key] = value
+		}
+	}
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			patch.Remove = append(patch.Remove, key)
+		}
+	}
+	if patch.Add == nil && patch.Remove == nil {
+		return nil
+	}
+	slices.Sort(patch.Remove)
+	return &patch
+}
+
+func diffInt64Set(current, desired []int64) *dto.Int64ListPatch {
+	var patch dto.Int64ListPatch
+	for _, value := range desired {
+		if !slices.Contains(current, value) && !slices.Contains(patch.Add, value) {
+			patch.Add = append(patch.Add, value)
+		}
+	}
+	for _, value := range current {
+		if !slices.Contains(desired, value) && !slices.Contains(patch.Remove, value) {
+			patch.Remove = append(patch.Remove, value)
+		}
+	}
+	if patch.Add == nil && patch.Remove == nil {
+		return nil
+	}
+	return &patch
+}
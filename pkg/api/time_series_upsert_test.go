@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestDiffTimeSeries(t *testing.T) {
+	current := &dto.TimeSeries{
+		ExternalId:         "a",
+		Name:               "Pump pressure",
+		Unit:               "bar",
+		Description:        "old",
+		Metadata:           dto.Metadata{"site": "oslo", "tag": "PT-1", "stale": "yes"},
+		SecurityCategories: []int64{1, 2},
+	}
+
+	t.Run("Equal time series produce no patch", func(t *testing.T) {
+		desired := &dto.TimeSeries{
+			ExternalId:         "a",
+			Name:               "Pump pressure",
+			Unit:               "bar",
+			Description:        "old",
+			Metadata:           dto.Metadata{"site": "oslo", "tag": "PT-1", "stale": "yes"},
+			SecurityCategories: []int64{2, 1},
+		}
+
+		if _, changed := diffTimeSeries(current, desired); changed {
+			t.Error("Expected no changes")
+		}
+	})
+
+	t.Run("Only differing fields are patched", func(t *testing.T) {
+		desired := &dto.TimeSeries{
+			ExternalId:         "a",
+			Name:               "Pump pressure",
+			Unit:               "bar",
+			IsStep:             true,
+			Metadata:           dto.Metadata{"site": "bergen", "tag": "PT-1"},
+			SecurityCategories: []int64{2, 3},
+		}
+
+		patch, changed := diffTimeSeries(current, desired)
+		if !changed {
+			t.Fatal("Expected changes")
+		}
+		if patch.Name != nil || patch.Unit != nil {
+			t.Error("Expected unchanged fields to be omitted")
+		}
+		if patch.Description == nil || !patch.Description.SetNull {
+			t.Error("Expected description to be cleared")
+		}
+		if patch.IsStep == nil || !*patch.IsStep.Set {
+			t.Error("Expected isStep to be set")
+		}
+		if len(patch.Metadata.Add) != 1 || patch.Metadata.Add["site"] != "bergen" {
+			t.Errorf("Unexpected metadata add %v", patch.Metadata.Add)
+		}
+		if len(patch.Metadata.Remove) != 1 || patch.Metadata.Remove[0] != "stale" {
+			t.Errorf("Unexpected metadata remove %v", patch.Metadata.Remove)
+		}
+		if patch.SecurityCategories.Add[0] != 3 || patch.SecurityCategories.Remove[0] != 1 {
+			t.Errorf("Unexpected security categories patch %+v", patch.SecurityCategories)
+		}
+	})
+}
+
+func TestTimeSeries_Upsert(t *testing.T) {
+	var createdBody, updatedBody string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/timeseries/byids"):
+			_, _ = w.Write([]byte(`{"items":[
+				{"externalId":"same","name":"Same"},
+				{"externalId":"changed","name":"Before"}
+			]}`))
+		case strings.HasSuffix(r.URL.Path, "/timeseries/update"):
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			out, _ := json.Marshal(body)
+			updatedBody = string(out)
+			_, _ = w.Write([]byte(`{"items":[{"externalId":"changed","name":"After"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/timeseries"):
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			out, _ := json.Marshal(body)
+			createdBody = string(out)
+			_, _ = w.Write([]byte(`{"items":[{"externalId":"new","name":"New"}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	result, err := client.TimeSeries.Upsert([]dto.TimeSeries{
+		{ExternalId: "same", Name: "Same"},
+		{ExternalId: "changed", Name: "After"},
+		{ExternalId: "new", Name: "New"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Created.Items) != 1 || len(result.Updated.Items) != 1 || result.Unchanged != 1 {
+		t.Errorf("Unexpected result: created=%d updated=%d unchanged=%d",
+			len(result.Created.Items), len(result.Updated.Items), result.Unchanged)
+	}
+	if !strings.Contains(createdBody, `"externalId":"new"`) {
+		t.Errorf("Unexpected create body %s", createdBody)
+	}
+	expectedUpdate := `{"items":[{"externalId":"changed","update":{"name":{"set":"After"}}}]}`
+	if updatedBody != expectedUpdate {
+		t.Errorf("Unexpected update body\n got: %s\nwant: %s", updatedBody, expectedUpdate)
+	}
+}
+
+func TestTimeSeries_Upsert_createConflict(t *testing.T) {
+	var creates int
+	var createdBodies []string
+	var updatedBody string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/timeseries/byids"):
+			// "raced" only exists once the other writer has created it
+			if creates == 0 {
+				_, _ = w.Write([]byte(`{"items":[]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"items":[{"externalId":"raced","name":"Theirs"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/timeseries/update"):
+			updatedBody = string(body)
+			_, _ = w.Write([]byte(`{"items":[{"externalId":"raced","name":"Ours"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/timeseries"):
+			creates++
+			createdBodies = append(createdBodies, string(body))
+			if creates == 1 {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"error":{"code":409,"message":"ExternalIds duplicated","duplicated":[{"externalId":"raced"}]}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"items":[{"externalId":"new","name":"New"}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	result, err := client.TimeSeries.Upsert([]dto.TimeSeries{
+		{ExternalId: "raced", Name: "Ours"},
+		{ExternalId: "new", Name: "New"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Created.Items) != 1 || len(result.Updated.Items) != 1 || result.Unchanged != 0 {
+		t.Errorf("Unexpected result: created=%d updated=%d unchanged=%d",
+			len(result.Created.Items), len(result.Updated.Items), result.Unchanged)
+	}
+	if len(createdBodies) != 2 || strings.Contains(createdBodies[1], `"raced"`) || !strings.Contains(createdBodies[1], `"new"`) {
+		t.Errorf("Expected the create to be retried without the conflicting item, got %v", createdBodies)
+	}
+	expectedUpdate := `{"items":[{"externalId":"raced","update":{"name":{"set":"Ours"}}}]}`
+	if updatedBody != expectedUpdate {
+		t.Errorf("Unexpected update body\n got: %s\nwant: %s", updatedBody, expectedUpdate)
+	}
+
+	// Conflicts CDF does not attribute to an externalId are returned
+	client = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/timeseries/byids") {
+			_, _ = w.Write([]byte(`{"items":[]}`))
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":{"code":409,"message":"Conflict"}}`))
+	})
+	if _, err := client.TimeSeries.Upsert([]dto.TimeSeries{{ExternalId: "new"}}); err == nil {
+		t.Error("Expected the conflict to be returned")
+	}
+}
+
+func TestTimeSeries_Upsert_requiresExternalId(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request to be sent")
+	})
+
+	if _, err := client.TimeSeries.Upsert([]dto.TimeSeries{{Name: "no external id"}}); err == nil {
+		t.Error("Expected error for missing externalId")
+	}
+}
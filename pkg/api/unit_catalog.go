@@ -0,0 +1,293 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// defaultUnitCatalogTTL is how long a fetched unit catalog is used before it
+// is fetched again.
+const (
+	defaultUnitCatalogTTL           = 24 * time.Hour
+	defaultUnitCatalogRetryInterval = time.Minute
+)
+
+// UnitCatalogConfig controls how a UnitCatalog loads and refreshes units.
+type UnitCatalogConfig struct {
+	// TTL is how long the catalog is used before it is fetched again. It
+	// defaults to 24 hours.
+	TTL time.Duration
+	// RetryInterval is how long a stale catalog is used after a failed
+	// refresh before CDF is tried again. It defaults to one minute.
+	RetryInterval time.Duration
+	// Path is an optional file the catalog is persisted to. A fresh copy on
+	// disk is used instead of calling CDF, and a stale one when CDF cannot be
+	// reached. A corrupt file is replaced with a freshly fetched catalog.
+	Path string
+}
+
+// UnitCatalog holds the unit catalog in memory, indexed by external ID,
+// alias name, symbol and quantity. It fetches the catalog with Units.List on
+// first use and again when it is older than the TTL. It is safe for
+// concurrent use.
+type UnitCatalog struct {
+	units  *Units
+	config UnitCatalogConfig
+	now    func() time.Time
+
+	mu           sync.Mutex
+	fetchedTime  time.Time
+	failedTime   time.Time
+	list         dto.UnitList
+	byExternalId map[string]int
+	byAlias      map[string][]int
+	bySymbol     map[string][]int
+	byQuantity   map[string][]int
+}
+
+// unitCatalogFile is the format of a persisted catalog.
+type unitCatalogFile struct {
+	FetchedTime int64      `json:"fetchedTime"`
+	Items       []dto.Unit `json:"items"`
+}
+
+// NewUnitCatalog creates a catalog that fetches units through u. u may be
+// nil to work only from the file in config.Path.
+func NewUnitCatalog(u *Units, config UnitCatalogConfig) *UnitCatalog {
+	if config.TTL <= 0 {
+		config.TTL = defaultUnitCatalogTTL
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultUnitCatalogRetryInterval
+	}
+	return &UnitCatalog{units: u, config: config, now: time.Now}
+}
+
+// Refresh fetches the catalog from CDF, regardless of its age, and persists
+// it when a path is configured.
+func (c *UnitCatalog) Refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refresh()
+}
+
+func (c *UnitCatalog) refresh() error {
+	if c.units == nil {
+		return errors.New("unit catalog has no client to fetch units with")
+	}
+	list, err := c.units.List()
+	if err != nil {
+		return fmt.Errorf("failed to fetch the unit catalog: %w", err)
+	}
+	c.index(list, c.now())
+	c.failedTime = time.Time{}
+
+	if c.config.Path != "" {
+		if err := c.save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load makes sure a catalog no older than the TTL is loaded. When it cannot
+// be refreshed, an older catalog is kept, no error is returned and the
+// refresh is not tried again for the retry interval. A file
+// that cannot be read or decoded is treated like a missing one: the catalog
+// is fetched from CDF and the file rewritten.
+func (c *UnitCatalog) load() error {
+	if c.byExternalId != nil && c.now().Sub(c.fetchedTime) < c.config.TTL {
+		return nil
+	}
+	var readErr error
+	if c.byExternalId == nil && c.config.Path != "" {
+		readErr = c.read()
+		if c.byExternalId != nil && c.now().Sub(c.fetchedTime) < c.config.TTL {
+			return nil
+		}
+	}
+
+	if c.byExternalId != nil && c.now().Sub(c.failedTime) < c.config.RetryInterval {
+		return nil
+	}
+
+	err := c.refresh()
+	if err != nil && c.byExternalId != nil {
+		c.failedTime = c.now()
+		return nil
+	}
+	if err != nil && readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		return errors.Join(readErr, err)
+	}
+	return err
+}
+
+func (c *UnitCatalog) index(list dto.UnitList, fetchedTime time.Time) {
+	c.list = list
+	c.fetchedTime = fetchedTime
+	c.byExternalId = make(map[string]int, len(list.Items))
+	c.byAlias = make(map[string][]int)
+	c.bySymbol = make(map[string][]int)
+	c.byQuantity = make(map[string][]int)
+	for i := range list.Items {
+		unit := &list.Items[i]
+		c.byExternalId[unit.ExternalId] = i
+		for _, alias := range unit.AliasNames {
+			c.byAlias[alias] = append(c.byAlias[alias], i)
+		}
+		if unit.Symbol != "" {
+			c.bySymbol[unit.Symbol] = append(c.bySymbol[unit.Symbol], i)
+		}
+		c.byQuantity[unit.Quantity] = append(c.byQuantity[unit.Quantity], i)
+	}
+}
+
+func (c *UnitCatalog) read() error {
+	data, err := os.ReadFile(c.config.Path)
+	if err != nil {
+		return err
+	}
+	var file unitCatalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to read the unit catalog %s: %w", c.config.Path, err)
+	}
+	c.index(dto.UnitList{Items: file.Items}, time.UnixMilli(file.FetchedTime))
+	return nil
+}
+
+// save writes the catalog to a temporary file and renames it, so readers
+// never see a partial file.
+func (c *UnitCatalog) save() error {
+	data, err := json.Marshal(unitCatalogFile{FetchedTime: c.fetchedTime.UnixMilli(), Items: c.list.Items})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.config.Path), filepath.Base(c.config.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save the unit catalog: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save the unit catalog: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save the unit catalog: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.config.Path); err != nil {
+		return fmt.Errorf("failed to save the unit catalog: %w", err)
+	}
+	return nil
+}
+
+func (c *UnitCatalog) lookup(index func() map[string][]int, key string) ([]dto.Unit, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	indices := index()[key]
+	units := make([]dto.Unit, len(indices))
+	for i, index := range indices {
+		units[i] = c.list.Items[index]
+	}
+	return units, nil
+}
+
+// Units returns the whole catalog.
+func (c *UnitCatalog) Units() (dto.UnitList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return dto.UnitList{}, err
+	}
+	return c.list, nil
+}
+
+// Unit returns the unit with the given external ID.
+func (c *UnitCatalog) Unit(externalId string) (dto.Unit, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return dto.Unit{}, err
+	}
+	index, ok := c.byExternalId[externalId]
+	if !ok {
+		return dto.Unit{}, fmt.Errorf("unit not found with ExternalId: %s", externalId)
+	}
+	return c.list.Items[index], nil
+}
+
+// ByAlias returns the units that list alias among their alias names.
+func (c *UnitCatalog) ByAlias(alias string) ([]dto.Unit, error) {
+	return c.lookup(func() map[string][]int { return c.byAlias }, alias)
+}
+
+// BySymbol returns the units with the given symbol, such as "°C".
+func (c *UnitCatalog) BySymbol(symbol string) ([]dto.Unit, error) {
+	return c.lookup(func() map[string][]int { return c.bySymbol }, symbol)
+}
+
+// ByQuantity returns the units of a quantity, such as "Pressure".
+func (c *UnitCatalog) ByQuantity(quantity string) ([]dto.Unit, error) {
+	return c.lookup(func() map[string][]int { return c.byQuantity }, quantity)
+}
+
+// Lookup returns the units a name may refer to: the unit with that external
+// ID, then units with it as an alias, then units with it as a symbol. Each
+// unit is returned once.
+func (c *UnitCatalog) Lookup(name string) ([]dto.Unit, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	var indices []int
+	if index, ok := c.byExternalId[name]; ok {
+		indices = append(indices, index)
+	}
+	for _, index := range slices.Concat(c.byAlias[name], c.bySymbol[name]) {
+		if !slices.Contains(indices, index) {
+			indices = append(indices, index)
+		}
+	}
+
+	units := make([]dto.Unit, len(indices))
+	for i, index := range indices {
+		units[i] = c.list.Items[index]
+	}
+	return units, nil
+}
+
+// Quantities returns the quantities in the catalog, sorted by name.
+func (c *UnitCatalog) Quantities() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	quantities := make([]string, 0, len(c.byQuantity))
+	for quantity := range c.byQuantity {
+		quantities = append(quantities, quantity)
+	}
+	slices.Sort(quantities)
+	return quantities, nil
+}
+
+// Converter returns a UnitConverter for the current catalog.
+func (c *UnitCatalog) Converter() (*UnitConverter, error) {
+	list, err := c.Units()
+	if err != nil {
+		return nil, err
+	}
+	return NewUnitConverter(list), nil
+}
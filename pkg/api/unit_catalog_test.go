@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func testCatalogUnits() dto.UnitList {
+	list := testUnitList()
+	list.Items[0].AliasNames = []string{"degC", "deg C", "Celsius"}
+	list.Items[1].AliasNames = []string{"degF"}
+	list.Items[3].AliasNames = []string{"bar", "bars"}
+	return list
+}
+
+// newCatalogTestClient serves the unit catalog and counts the requests. When
+// fail is set, it responds with an error instead.
+func newCatalogTestClient(t *testing.T, requests *atomic.Int32, fail *atomic.Bool) CogniteClient {
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if fail != nil && fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(testCatalogUnits())
+	})
+}
+
+func TestUnitCatalog_lookups(t *testing.T) {
+	var requests atomic.Int32
+	client := newCatalogTestClient(t, &requests, nil)
+	catalog := NewUnitCatalog(&client.Units, UnitCatalogConfig{})
+
+	unit, err := catalog.Unit("pressure:bar")
+	if err != nil || unit.Symbol != "bar" {
+		t.Fatalf("Expected the bar unit, got %+v, %v", unit, err)
+	}
+	if _, err := catalog.Unit("pressure:psi"); err == nil {
+		t.Error("Expected an error for an unknown unit")
+	}
+
+	externalIds := func(units []dto.Unit, err error) []string {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var ids []string
+		for _, unit := range units {
+			ids = append(ids, unit.ExternalId)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name     string
+		result   []string
+		expected []string
+	}{
+		{"alias", externalIds(catalog.ByAlias("degC")), []string{"temperature:deg_c"}},
+		{"symbol", externalIds(catalog.BySymbol("K")), []string{"temperature:k"}},
+		{"quantity", externalIds(catalog.ByQuantity("Pressure")), []string{"pressure:bar", "pressure:pa"}},
+		{"unknown quantity", externalIds(catalog.ByQuantity("Length")), nil},
+		// "bar" is the symbol and an alias of the same unit
+		{"lookup", externalIds(catalog.Lookup("bar")), []string{"pressure:bar"}},
+		{"lookup external id", externalIds(catalog.Lookup("temperature:k")), []string{"temperature:k"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, tt.result)
+			}
+		})
+	}
+
+	quantities, err := catalog.Quantities()
+	if err != nil || len(quantities) != 2 || quantities[0] != "Pressure" || quantities[1] != "Temperature" {
+		t.Errorf("Expected [Pressure Temperature], got %v, %v", quantities, err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected the catalog to be fetched once, got %d requests", requests.Load())
+	}
+}
+
+func TestUnitCatalog_TTL(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	client := newCatalogTestClient(t, &requests, &fail)
+	catalog := NewUnitCatalog(&client.Units, UnitCatalogConfig{TTL: time.Hour})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	catalog.now = func() time.Time { return now }
+
+	if _, err := catalog.Units(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now = now.Add(59 * time.Minute)
+	if _, err := catalog.Units(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request within the TTL, got %d", requests.Load())
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := catalog.Units(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected a refresh after the TTL, got %d requests", requests.Load())
+	}
+
+	// A stale catalog is kept when the refresh fails, and CDF is not asked
+	// again until the retry interval has passed
+	fail.Store(true)
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := catalog.Unit("pressure:bar"); err != nil {
+			t.Errorf("Expected the stale catalog to be used, got %v", err)
+		}
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected one failed refresh, got %d requests", requests.Load()-2)
+	}
+	now = now.Add(time.Minute)
+	if _, err := catalog.Unit("pressure:bar"); err != nil {
+		t.Errorf("Expected the stale catalog to be used, got %v", err)
+	}
+	if requests.Load() != 4 {
+		t.Errorf("Expected a retry after the retry interval, got %d requests", requests.Load())
+	}
+	if err := catalog.Refresh(); err == nil {
+		t.Error("Expected an explicit refresh to fail")
+	}
+}
+
+func TestUnitCatalog_persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.json")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var requests atomic.Int32
+	var fail atomic.Bool
+	client := newCatalogTestClient(t, &requests, &fail)
+	catalog := NewUnitCatalog(&client.Units, UnitCatalogConfig{TTL: time.Hour, Path: path})
+	catalog.now = func() time.Time { return now }
+	if _, err := catalog.Units(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A fresh file is used without calling CDF
+	cached := NewUnitCatalog(&client.Units, UnitCatalogConfig{TTL: time.Hour, Path: path})
+	cached.now = func() time.Time { return now.Add(30 * time.Minute) }
+	if _, err := cached.Unit("pressure:bar"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected the file to be used, got %d requests", requests.Load())
+	}
+
+	// A stale file is used when CDF cannot be reached
+	fail.Store(true)
+	stale := NewUnitCatalog(&client.Units, UnitCatalogConfig{TTL: time.Hour, Path: path})
+	stale.now = func() time.Time { return now.Add(48 * time.Hour) }
+	if _, err := stale.Unit("temperature:deg_f"); err != nil {
+		t.Errorf("Expected the stale file to be used, got %v", err)
+	}
+
+	// Without a client, the catalog works from the file alone
+	offline := NewUnitCatalog(nil, UnitCatalogConfig{Path: path})
+	offline.now = func() time.Time { return now }
+	converter, err := offline.Converter()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value, err := converter.Convert(1, "pressure:bar", "pressure:pa"); err != nil || value != 100000 {
+		t.Errorf("Expected 100000, got %v, %v", value, err)
+	}
+
+	missing := NewUnitCatalog(nil, UnitCatalogConfig{Path: filepath.Join(t.TempDir(), "missing.json")})
+	if _, err := missing.Units(); err == nil {
+		t.Error("Expected an error without a client or a file")
+	}
+}
+
+func TestUnitCatalog_corruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.json")
+	data, err := json.Marshal(unitCatalogFile{FetchedTime: time.Now().UnixMilli(), Items: testCatalogUnits().Items})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// A write cut short leaves a truncated file behind
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	offline := NewUnitCatalog(nil, UnitCatalogConfig{Path: path})
+	if _, err := offline.Units(); err == nil || !strings.Contains(err.Error(), "failed to read the unit catalog") {
+		t.Errorf("Expected the read error without a client, got %v", err)
+	}
+
+	var requests atomic.Int32
+	client := newCatalogTestClient(t, &requests, nil)
+	catalog := NewUnitCatalog(&client.Units, UnitCatalogConfig{Path: path})
+	if unit, err := catalog.Unit("pressure:bar"); err != nil || unit.Symbol != "bar" {
+		t.Fatalf("Expected the catalog to be fetched, got %+v, %v", unit, err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected the catalog to be fetched once, got %d requests", requests.Load())
+	}
+
+	// The file was rewritten and is used without calling CDF
+	cached := NewUnitCatalog(nil, UnitCatalogConfig{Path: path})
+	if _, err := cached.Unit("pressure:bar"); err != nil {
+		t.Errorf("Expected the rewritten file to be used, got %v", err)
+	}
+}
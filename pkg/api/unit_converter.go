@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// UnitConverter converts values between units of the unit catalog without
+// calling CDF. Each unit's conversion maps a value to its quantity's base
+// unit as value*multiplier + offset.
+type UnitConverter struct {
+	units map[string]dto.Unit
+}
+
+// NewUnitConverter creates a converter from a unit catalog, such as the
+// result of Units.List or a copy of it cached on disk.
+func NewUnitConverter(units dto.UnitList) *UnitConverter {
+	c := &UnitConverter{units: make(map[string]dto.Unit, len(units.Items))}
+	for _, unit := range units.Items {
+		c.units[unit.ExternalId] = unit
+	}
+	return c
+}
+
+// Converter fetches the unit catalog and creates a converter from it.
+func (u *Units) Converter() (*UnitConverter, error) {
+	units, err := u.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the unit catalog: %w", err)
+	}
+	return NewUnitConverter(units), nil
+}
+
+// Unit returns the unit with the given external ID.
+func (c *UnitConverter) Unit(externalId string) (dto.Unit, bool) {
+	unit, ok := c.units[externalId]
+	return unit, ok
+}
+
+// ConvertFunc returns a function converting values from one unit to
+// another. Both units must be in the catalog and measure the same quantity.
+func (c *UnitConverter) ConvertFunc(from, to string) (func(float64) float64, error) {
+	fromUnit, ok := c.units[from]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q", from)
+	}
+	toUnit, ok := c.units[to]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q", to)
+	}
+	if fromUnit.Quantity != toUnit.Quantity {
+		return nil, fmt.Errorf("cannot convert %s (%s) to %s (%s): different quantities",
+			from, fromUnit.Quantity, to, toUnit.Quantity)
+	}
+	if from == to {
+		return func(value float64) float64 { return value }, nil
+	}
+	if toUnit.Conversion.Multiplier == 0 {
+		return nil, fmt.Errorf("unit %q has a zero conversion multiplier", to)
+	}
+
+	fromConversion, toConversion := fromUnit.Conversion, toUnit.Conversion
+	return func(value float64) float64 {
+		base := value*fromConversion.Multiplier + fromConversion.Offset
+		return (base - toConversion.Offset) / toConversion.Multiplier
+	}, nil
+}
+
+// Convert converts a single value from one unit to another.
+func (c *UnitConverter) Convert(value float64, from, to string) (float64, error) {
+	convert, err := c.ConvertFunc(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return convert(value), nil
+}
+
+// ConvertDatapoints returns a copy of the datapoints converted from one unit
+// to another. Null values and status codes are kept as they are.
+func (c *UnitConverter) ConvertDatapoints(datapoints *dto.NumericDatapoints, from, to string) (*dto.NumericDatapoints, error) {
+	convert, err := c.ConvertFunc(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &dto.NumericDatapoints{Datapoints: make([]*dto.NumericDatapoint, len(datapoints.GetDatapoints()))}
+	for i, dp := range datapoints.GetDatapoints() {
+		value := dp.GetValue()
+		if !dp.GetNullValue() {
+			value = convert(value)
+		}
+		converted.Datapoints[i] = &dto.NumericDatapoint{
+			Timestamp: dp.GetTimestamp(),
+			Value:     value,
+			NullValue: dp.GetNullValue(),
+			Status:    dp.GetStatus(),
+		}
+	}
+	return converted, nil
+}
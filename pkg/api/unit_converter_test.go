@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func testUnitList() dto.UnitList {
+	return dto.UnitList{Items: []dto.Unit{
+		{ExternalId: "temperature:deg_c", Symbol: "°C", Quantity: "Temperature", Conversion: dto.UnitConversion{Multiplier: 1, Offset: 273.15}},
+		{ExternalId: "temperature:deg_f", Symbol: "°F", Quantity: "Temperature", Conversion: dto.UnitConversion{Multiplier: 5.0 / 9.0, Offset: 255.3722222222222}},
+		{ExternalId: "temperature:k", Symbol: "K", Quantity: "Temperature", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "pressure:bar", Symbol: "bar", Quantity: "Pressure", Conversion: dto.UnitConversion{Multiplier: 100000}},
+		{ExternalId: "pressure:pa", Symbol: "Pa", Quantity: "Pressure", Conversion: dto.UnitConversion{Multiplier: 1}},
+	}}
+}
+
+func TestUnitConverter_Convert(t *testing.T) {
+	converter := NewUnitConverter(testUnitList())
+
+	tests := []struct {
+		name        string
+		value       float64
+		from        string
+		to          string
+		expected    float64
+		expectedErr bool
+	}{
+		{name: "celsius to fahrenheit", value: 100, from: "temperature:deg_c", to: "temperature:deg_f", expected: 212},
+		{name: "fahrenheit to kelvin", value: 32, from: "temperature:deg_f", to: "temperature:k", expected: 273.15},
+		{name: "bar to pascal", value: 2.5, from: "pressure:bar", to: "pressure:pa", expected: 250000},
+		{name: "same unit", value: 42, from: "pressure:bar", to: "pressure:bar", expected: 42},
+		{name: "different quantities", value: 1, from: "pressure:bar", to: "temperature:k", expectedErr: true},
+		{name: "unknown unit", value: 1, from: "length:m", to: "length:ft", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.value, tt.from, tt.to)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(result-tt.expected) > 1e-9 {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestUnitConverter_ConvertDatapoints(t *testing.T) {
+	converter := NewUnitConverter(testUnitList())
+	datapoints := &dto.NumericDatapoints{Datapoints: []*dto.NumericDatapoint{
+		{Timestamp: 0, Value: 1},
+		{Timestamp: 1000, NullValue: true, Status: &dto.Status{Code: 2147483648, Symbol: "Bad"}},
+	}}
+
+	converted, err := converter.ConvertDatapoints(datapoints, "pressure:bar", "pressure:pa")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := converted.Datapoints[0].GetValue(); got != 100000 {
+		t.Errorf("Expected 100000, got %v", got)
+	}
+	if !converted.Datapoints[1].GetNullValue() || converted.Datapoints[1].GetStatus().GetSymbol() != "Bad" {
+		t.Error("Expected the null value and its status to be kept")
+	}
+	if datapoints.Datapoints[0].GetValue() != 1 {
+		t.Error("Expected the input datapoints to be left unchanged")
+	}
+}
+
+func TestUnits_Converter(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/test-project/units" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(testUnitList())
+	})
+
+	converter, err := client.Units.Converter()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if unit, ok := converter.Unit("pressure:bar"); !ok || unit.Symbol != "bar" {
+		t.Errorf("Expected the bar unit, got %+v", unit)
+	}
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// Confidence of each kind of unit match. Matches that ignore case score
+// below normalized ones, since case tells SI prefixes apart (MW and mW).
+// Similar spellings score up to unitMatchSimilar times their similarity.
+const (
+	unitMatchExact      = 1.0
+	unitMatchSymbol     = 0.95
+	unitMatchNormalized = 0.9
+	unitMatchFolded     = 0.8
+	unitMatchSimilar    = 0.7
+	// unitMatchMinSimilarity is the lowest similarity considered a match.
+	unitMatchMinSimilarity = 0.8
+)
+
+// UnitMatch is a unit proposed for a free-text unit string. Reason tells
+// what matched: "externalId", "alias", "symbol", "normalized", "folded" or
+// "similar".
+type UnitMatch struct {
+	Unit       dto.Unit
+	Confidence float64
+	Reason     string
+}
+
+// UnitMatcher matches free-text unit strings such as "bar g", "°C" or "m3/h"
+// to units of the unit catalog.
+type UnitMatcher struct {
+	units      []dto.Unit
+	exact      map[string][]unitMatchKey
+	normalized map[string][]int
+	folded     map[string][]int
+}
+
+type unitMatchKey struct {
+	index  int
+	reason string
+}
+
+// NewUnitMatcher creates a matcher for the units of a unit catalog.
+func NewUnitMatcher(units dto.UnitList) *UnitMatcher {
+	m := &UnitMatcher{
+		units:      units.Items,
+		exact:      make(map[string][]unitMatchKey),
+		normalized: make(map[string][]int),
+		folded:     make(map[string][]int),
+	}
+	for i := range units.Items {
+		unit := &units.Items[i]
+		m.exact[unit.ExternalId] = append(m.exact[unit.ExternalId], unitMatchKey{i, "externalId"})
+		for _, alias := range unit.AliasNames {
+			m.exact[alias] = append(m.exact[alias], unitMatchKey{i, "alias"})
+		}
+		if unit.Symbol != "" {
+			m.exact[unit.Symbol] = append(m.exact[unit.Symbol], unitMatchKey{i, "symbol"})
+		}
+
+		for _, name := range slices.Concat(unit.AliasNames, []string{unit.Symbol, unit.Name, unit.LongName}) {
+			key := normalizeUnitText(name)
+			if key == "" {
+				continue
+			}
+			if !slices.Contains(m.normalized[key], i) {
+				m.normalized[key] = append(m.normalized[key], i)
+			}
+			if folded := strings.ToLower(key); !slices.Contains(m.folded[folded], i) {
+				m.folded[folded] = append(m.folded[folded], i)
+			}
+		}
+	}
+	return m
+}
+
+// Candidates returns every unit text may refer to, best first, each with
+// the confidence of its best match.
+func (m *UnitMatcher) Candidates(text string) []UnitMatch {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	best := make(map[int]UnitMatch)
+	consider := func(index int, confidence float64, reason string) {
+		if current, ok := best[index]; !ok || confidence > current.Confidence {
+			best[index] = UnitMatch{Unit: m.units[index], Confidence: confidence, Reason: reason}
+		}
+	}
+
+	for _, key := range m.exact[text] {
+		confidence := unitMatchExact
+		if key.reason == "symbol" {
+			confidence = unitMatchSymbol
+		}
+		consider(key.index, confidence, key.reason)
+	}
+
+	normalized := normalizeUnitText(text)
+	for _, index := range m.normalized[normalized] {
+		consider(index, unitMatchNormalized, "normalized")
+	}
+	folded := strings.ToLower(normalized)
+	for _, index := range m.folded[folded] {
+		consider(index, unitMatchFolded, "folded")
+	}
+	if len(best) == 0 && len([]rune(folded)) >= 3 {
+		for key, indices := range m.folded {
+			similarity := textSimilarity(folded, key)
+			if similarity < unitMatchMinSimilarity {
+				continue
+			}
+			for _, index := range indices {
+				consider(index, unitMatchSimilar*similarity, "similar")
+			}
+		}
+	}
+
+	matches := make([]UnitMatch, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, match)
+	}
+	slices.SortFunc(matches, func(a, b UnitMatch) int {
+		if a.Confidence != b.Confidence {
+			if a.Confidence > b.Confidence {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Unit.ExternalId, b.Unit.ExternalId)
+	})
+	return matches
+}
+
+// Match returns the best unit for text. When several units match equally
+// well, the confidence is divided between them.
+func (m *UnitMatcher) Match(text string) (UnitMatch, bool) {
+	candidates := m.Candidates(text)
+	if len(candidates) == 0 {
+		return UnitMatch{}, false
+	}
+	match := candidates[0]
+	ties := 1
+	for _, candidate := range candidates[1:] {
+		if candidate.Confidence == match.Confidence {
+			ties++
+		}
+	}
+	match.Confidence /= float64(ties)
+	return match, true
+}
+
+// normalizeUnitText folds the spelling variants of a unit string:
+// whitespace, degree signs, superscripts and exponent markers, so that
+// "m³/h", "m^3/h" and "m3 / h" compare equal. Case is kept, since it
+// separates prefixes such as M (mega) and m (milli).
+func normalizeUnitText(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		switch r {
+		case ' ', '\t', '^', '(', ')', '_':
+		case '°', 'º':
+			sb.WriteString("deg")
+		case '²':
+			sb.WriteByte('2')
+		case '³':
+			sb.WriteByte('3')
+		case 'µ', 'μ':
+			sb.WriteByte('u')
+		case '·', '*':
+			sb.WriteByte('.')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// textSimilarity returns 1 minus the edit distance between a and b relative
+// to the longer of the two.
+func textSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+// UnitMatchJobConfig controls TimeSeries.MatchUnits.
+type UnitMatchJobConfig struct {
+	// MinConfidence is the lowest confidence proposed. It defaults to 0.9,
+	// which only accepts exact and normalized matches, not ones that differ
+	// in case.
+	MinConfidence float64
+	// Overwrite also proposes units for time series that already have a
+	// unitExternalId.
+	Overwrite bool
+	// Apply sets the proposed unitExternalIds with Update. Otherwise the
+	// job only reports what it would do.
+	Apply bool
+}
+
+// UnitProposal is a unitExternalId proposed for a time series from its
+// free-text unit. Identity holds the identifier the time series is updated
+// by: its ID when known, otherwise its external ID or instance ID.
+type UnitProposal struct {
+	Identity dto.Identity
+	Unit     string
+	Match    UnitMatch
+}
+
+// UnitMatchReport is the result of TimeSeries.MatchUnits. Unmatched holds
+// the time series whose unit had no match above MinConfidence, with their
+// best match if any, and Updated the time series returned by Update when
+// Apply is set.
+type UnitMatchReport struct {
+	Proposals []UnitProposal
+	Unmatched []UnitProposal
+	Updated   dto.TimeSeriesList
+}
+
+// MatchUnits proposes a unitExternalId for each time series in list that has
+// a free-text unit but no unitExternalId, and sets them when config.Apply is
+// true. list is typically the result of Filter.
+func (t *TimeSeries) MatchUnits(list dto.TimeSeriesList, matcher *UnitMatcher, config UnitMatchJobConfig) (UnitMatchReport, error) {
+	if config.MinConfidence <= 0 {
+		config.MinConfidence = unitMatchNormalized
+	}
+
+	var report UnitMatchReport
+	for i := range list.Items {
+		ts := &list.Items[i]
+		if ts.Unit == "" || (ts.UnitExternalId != "" && !config.Overwrite) {
+			continue
+		}
+		match, ok := matcher.Match(ts.Unit)
+		proposal := UnitProposal{Identity: timeSeriesIdentity(ts), Unit: ts.Unit, Match: match}
+		switch {
+		case !ok || match.Confidence < config.MinConfidence:
+			report.Unmatched = append(report.Unmatched, proposal)
+		case match.Unit.ExternalId != ts.UnitExternalId:
+			report.Proposals = append(report.Proposals, proposal)
+		}
+	}
+
+	if !config.Apply || len(report.Proposals) == 0 {
+		return report, nil
+	}
+
+	updates := make([]dto.TimeSeriesUpdate, len(report.Proposals))
+	for i, proposal := range report.Proposals {
+		updates[i] = dto.TimeSeriesUpdate{
+			Id:         proposal.Identity.Id,
+			ExternalId: proposal.Identity.ExternalId,
+			InstanceId: proposal.Identity.InstanceId,
+			Update: dto.TimeSeriesPatch{
+				UnitExternalId: &dto.StringPatch{Set: &report.Proposals[i].Match.Unit.ExternalId},
+			},
+		}
+	}
+	updated, err := t.Update(updates)
+	report.Updated = updated
+	if err != nil {
+		return report, fmt.Errorf("failed to apply unit proposals: %w", err)
+	}
+	return report, nil
+}
+
+// timeSeriesIdentity returns a single identifier of ts, since CDF rejects
+// update items with more than one.
+func timeSeriesIdentity(ts *dto.TimeSeries) dto.Identity {
+	switch {
+	case ts.Id != 0:
+		return dto.Identity{Id: ts.Id}
+	case ts.ExternalId != "":
+		return dto.Identity{ExternalId: ts.ExternalId}
+	case ts.InstanceId.Space != "":
+		return dto.Identity{InstanceId: &dto.InstanceId{Space: ts.InstanceId.Space, ExternalId: ts.InstanceId.ExternalId}}
+	}
+	return dto.Identity{}
+}
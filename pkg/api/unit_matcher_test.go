@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func testMatcherUnits() dto.UnitList {
+	return dto.UnitList{Items: []dto.Unit{
+		{ExternalId: "temperature:deg_c", Name: "DEG_C", LongName: "degree Celsius", Symbol: "°C", AliasNames: []string{"degC", "deg C", "Celsius"}, Quantity: "Temperature"},
+		{ExternalId: "pressure:bar", Name: "BAR", LongName: "bar", Symbol: "bar", AliasNames: []string{"bar", "bars"}, Quantity: "Pressure"},
+		{ExternalId: "pressure:bar-g", Name: "BAR_G", LongName: "bar gauge", Symbol: "bar(g)", AliasNames: []string{"barg"}, Quantity: "Pressure"},
+		{ExternalId: "volume_flow_rate:m3-per-hr", Name: "M3-PER-HR", LongName: "cubic metre per hour", Symbol: "m³/h", AliasNames: []string{"m3/hr"}, Quantity: "Volume Flow Rate"},
+		{ExternalId: "electric_charge:c", Name: "C", LongName: "coulomb", Symbol: "C", Quantity: "Electric Charge"},
+		{ExternalId: "temperature:deg_c_alt", Name: "DEG_C_ALT", LongName: "celsius (alternative)", Symbol: "C", Quantity: "Temperature"},
+		{ExternalId: "power:megaw", Name: "MegaW", LongName: "megawatt", Symbol: "MW", Quantity: "Power"},
+		{ExternalId: "power:milliw", Name: "MilliW", LongName: "milliwatt", Symbol: "mW", Quantity: "Power"},
+	}}
+}
+
+func TestUnitMatcher_Match(t *testing.T) {
+	matcher := NewUnitMatcher(testMatcherUnits())
+
+	tests := []struct {
+		text       string
+		expected   string
+		confidence float64
+		reason     string
+	}{
+		{text: "temperature:deg_c", expected: "temperature:deg_c", confidence: 1, reason: "externalId"},
+		{text: "degC", expected: "temperature:deg_c", confidence: 1, reason: "alias"},
+		{text: "°C", expected: "temperature:deg_c", confidence: 0.95, reason: "symbol"},
+		{text: "°c", expected: "temperature:deg_c", confidence: 0.8, reason: "folded"},
+		{text: "bar g", expected: "pressure:bar-g", confidence: 0.9, reason: "normalized"},
+		{text: "m3/h", expected: "volume_flow_rate:m3-per-hr", confidence: 0.9, reason: "normalized"},
+		{text: " M^3/H ", expected: "volume_flow_rate:m3-per-hr", confidence: 0.8, reason: "folded"},
+		// Case separates the prefixes mega and milli
+		{text: "MW", expected: "power:megaw", confidence: 0.95, reason: "symbol"},
+		{text: "mW", expected: "power:milliw", confidence: 0.95, reason: "symbol"},
+		{text: "M W", expected: "power:megaw", confidence: 0.9, reason: "normalized"},
+		{text: "mw", expected: "power:megaw", confidence: 0.8 / 2, reason: "folded"},
+		{text: "celcius", expected: "temperature:deg_c", confidence: 0.7 * 6 / 7, reason: "similar"},
+		// Two units share the symbol "C"
+		{text: "C", expected: "electric_charge:c", confidence: 0.95 / 2, reason: "symbol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			match, ok := matcher.Match(tt.text)
+			if !ok {
+				t.Fatal("Expected a match")
+			}
+			if match.Unit.ExternalId != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, match.Unit.ExternalId)
+			}
+			if math.Abs(match.Confidence-tt.confidence) > 1e-9 {
+				t.Errorf("Expected confidence %v, got %v", tt.confidence, match.Confidence)
+			}
+			if match.Reason != tt.reason {
+				t.Errorf("Expected reason %s, got %s", tt.reason, match.Reason)
+			}
+		})
+	}
+
+	for _, text := range []string{"", "furlongs per fortnight"} {
+		if match, ok := matcher.Match(text); ok {
+			t.Errorf("Expected no match for %q, got %s", text, match.Unit.ExternalId)
+		}
+	}
+}
+
+func TestTimeSeries_MatchUnits(t *testing.T) {
+	var requests int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v1/projects/test-project/timeseries/update" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"items":[{"id":1,"update":{"unitExternalId":{"set":"pressure:bar-g"}}},` +
+			`{"id":4,"update":{"unitExternalId":{"set":"temperature:deg_c"}}},` +
+			`{"externalId":"legacy-flow","update":{"unitExternalId":{"set":"volume_flow_rate:m3-per-hr"}}},` +
+			`{"instanceId":{"space":"site-oslo","externalId":"pump-pressure"},"update":{"unitExternalId":{"set":"pressure:bar"}}}]}`
+		if string(body) != expected {
+			t.Errorf("Expected body %s, got %s", expected, body)
+		}
+		_ = json.NewEncoder(w).Encode(dto.TimeSeriesList{Items: []dto.TimeSeries{{Id: 1}, {Id: 4}, {Id: 6}, {Id: 7}}})
+	})
+
+	list := dto.TimeSeriesList{Items: []dto.TimeSeries{
+		{Id: 1, Unit: "bar g"},
+		{Id: 2, Unit: "degC", UnitExternalId: "temperature:deg_c"},
+		{Id: 3, Unit: "celcius"},
+		{Id: 4, Unit: "°C"},
+		{Id: 5},
+		{ExternalId: "legacy-flow", Unit: "m3/h"},
+		{InstanceId: dto.InstanceId{Space: "site-oslo", ExternalId: "pump-pressure"}, Unit: "bars"},
+	}}
+	matcher := NewUnitMatcher(testMatcherUnits())
+
+	report, err := client.TimeSeries.MatchUnits(list, matcher, UnitMatchJobConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Proposals) != 4 || report.Proposals[0].Identity.Id != 1 || report.Proposals[1].Identity.Id != 4 ||
+		report.Proposals[2].Identity.ExternalId != "legacy-flow" || report.Proposals[3].Identity.InstanceId == nil {
+		t.Errorf("Expected proposals for 1, 4, legacy-flow and pump-pressure, got %+v", report.Proposals)
+	}
+	if len(report.Unmatched) != 1 || report.Unmatched[0].Identity.Id != 3 || report.Unmatched[0].Match.Unit.ExternalId != "temperature:deg_c" {
+		t.Errorf("Expected 3 to be unmatched with its best guess, got %+v", report.Unmatched)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests without Apply, got %d", requests)
+	}
+
+	report, err = client.TimeSeries.MatchUnits(list, matcher, UnitMatchJobConfig{Apply: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 1 || len(report.Updated.Items) != 4 {
+		t.Errorf("Expected one update request for 4 time series, got %d requests and %d updated", requests, len(report.Updated.Items))
+	}
+}
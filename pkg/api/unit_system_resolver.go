@@ -0,0 +1,78 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// UnitSystemResolver tells which unit datapoints come back in when they are
+// fetched with a TargetUnitSystem, without fetching them.
+type UnitSystemResolver struct {
+	systems map[string]map[string]string
+	units   map[string]dto.Unit
+}
+
+// NewUnitSystemResolver creates a resolver from the unit systems and the
+// unit catalog, such as the results of Units.ListSystems and Units.List.
+func NewUnitSystemResolver(systems dto.UnitSystemList, units dto.UnitList) *UnitSystemResolver {
+	r := &UnitSystemResolver{
+		systems: make(map[string]map[string]string, len(systems.Items)),
+		units:   make(map[string]dto.Unit, len(units.Items)),
+	}
+	for _, system := range systems.Items {
+		quantities := make(map[string]string, len(system.Quantities))
+		for _, quantity := range system.Quantities {
+			quantities[quantity.Name] = quantity.UnitExternalId
+		}
+		r.systems[system.Name] = quantities
+	}
+	for _, unit := range units.Items {
+		r.units[unit.ExternalId] = unit
+	}
+	return r
+}
+
+// SystemResolver fetches the unit systems and the unit catalog and creates a
+// resolver from them.
+func (u *Units) SystemResolver() (*UnitSystemResolver, error) {
+	systems, err := u.ListSystems()
+	if err != nil {
+		return nil, err
+	}
+	units, err := u.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the unit catalog: %w", err)
+	}
+	return NewUnitSystemResolver(systems, units), nil
+}
+
+// Resolve returns the unit that datapoints of a time series with the given
+// unitExternalId are converted to in a unit system.
+func (r *UnitSystemResolver) Resolve(unitExternalId string, system string) (dto.Unit, error) {
+	if unitExternalId == "" {
+		return dto.Unit{}, fmt.Errorf("cannot resolve unit system %s for a time series without unitExternalId", system)
+	}
+	unit, ok := r.units[unitExternalId]
+	if !ok {
+		return dto.Unit{}, fmt.Errorf("unknown unit %q", unitExternalId)
+	}
+	return r.ResolveQuantity(unit.Quantity, system)
+}
+
+// ResolveQuantity returns the unit a unit system uses for a quantity.
+func (r *UnitSystemResolver) ResolveQuantity(quantity string, system string) (dto.Unit, error) {
+	quantities, ok := r.systems[system]
+	if !ok {
+		return dto.Unit{}, fmt.Errorf("unknown unit system %q", system)
+	}
+	externalId, ok := quantities[quantity]
+	if !ok {
+		return dto.Unit{}, fmt.Errorf("unit system %s has no unit for quantity %s", system, quantity)
+	}
+	unit, ok := r.units[externalId]
+	if !ok {
+		return dto.Unit{}, fmt.Errorf("unit system %s refers to unknown unit %q", system, externalId)
+	}
+	return unit, nil
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUnitSystemResolver_Resolve(t *testing.T) {
+	resolver := NewUnitSystemResolver(testUnitSystems(), testUnitList())
+
+	tests := []struct {
+		name           string
+		unitExternalId string
+		system         string
+		expected       string
+		expectedErr    string
+	}{
+		{name: "celsius in SI", unitExternalId: "temperature:deg_c", system: "SI", expected: "temperature:k"},
+		{name: "celsius in Imperial", unitExternalId: "temperature:deg_c", system: "Imperial", expected: "temperature:deg_f"},
+		{name: "bar in SI", unitExternalId: "pressure:bar", system: "SI", expected: "pressure:pa"},
+		{name: "quantity missing from system", unitExternalId: "pressure:bar", system: "Imperial", expectedErr: "no unit for quantity Pressure"},
+		{name: "unknown system", unitExternalId: "pressure:bar", system: "Metric", expectedErr: "unknown unit system"},
+		{name: "unknown unit", unitExternalId: "length:m", system: "SI", expectedErr: "unknown unit"},
+		{name: "no unit", unitExternalId: "", system: "SI", expectedErr: "without unitExternalId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit, err := resolver.Resolve(tt.unitExternalId, tt.system)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if unit.ExternalId != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, unit.ExternalId)
+			}
+		})
+	}
+}
+
+func TestUnits_SystemResolver(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/projects/test-project/units/systems":
+			_ = json.NewEncoder(w).Encode(testUnitSystems())
+		case "/api/v1/projects/test-project/units":
+			_ = json.NewEncoder(w).Encode(testUnitList())
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	resolver, err := client.Units.SystemResolver()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	unit, err := resolver.Resolve("temperature:deg_f", "SI")
+	if err != nil || unit.ExternalId != "temperature:k" {
+		t.Errorf("Expected temperature:k, got %s, %v", unit.ExternalId, err)
+	}
+}
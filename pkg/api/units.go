@@ -45,3 +45,42 @@ func (u *Units) List() (dto.UnitList, error) {
 
 	return unitList, nil
 }
+
+// unitsByIdsLimit is the maximum number of units per byids request.
+const unitsByIdsLimit = 1000
+
+// Retrieve fetches units by external ID.
+func (u *Units) Retrieve(externalIds []string) (dto.UnitList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/units/byids", u.Client.ClientConfig.Project)
+
+	var retrieved dto.UnitList
+	for start := 0; start < len(externalIds); start += unitsByIdsLimit {
+		end := min(start+unitsByIdsLimit, len(externalIds))
+		items := make([]dto.Identity, 0, end-start)
+		for _, externalId := range externalIds[start:end] {
+			items = append(items, dto.Identity{ExternalId: externalId})
+		}
+
+		var unitList dto.UnitList
+		body := map[string]interface{}{"items": items}
+		if err := doJSONRequest(u.Client, "POST", endpoint, body, &unitList); err != nil {
+			return retrieved, fmt.Errorf("failed to retrieve units: %w", err)
+		}
+		retrieved.Items = append(retrieved.Items, unitList.Items...)
+	}
+
+	return retrieved, nil
+}
+
+// ListSystems lists the unit systems, such as SI, Imperial and Default, with
+// the unit each uses for every quantity.
+func (u *Units) ListSystems() (dto.UnitSystemList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/units/systems", u.Client.ClientConfig.Project)
+
+	var systemList dto.UnitSystemList
+	if err := doJSONRequest(u.Client, "GET", endpoint, nil, &systemList); err != nil {
+		return dto.UnitSystemList{}, fmt.Errorf("failed to list unit systems: %w", err)
+	}
+
+	return systemList, nil
+}
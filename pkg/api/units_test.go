@@ -1,7 +1,12 @@
 package api
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
 	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
 )
 
 func TestUnits_struct(t *testing.T) {
@@ -23,3 +28,57 @@ func TestUnits_struct(t *testing.T) {
 		t.Error("Expected Units.Client to be properly initialized")
 	}
 }
+
+func testUnitSystems() dto.UnitSystemList {
+	return dto.UnitSystemList{Items: []dto.UnitSystem{
+		{Name: "SI", Quantities: []dto.UnitSystemQuantity{
+			{Name: "Temperature", UnitExternalId: "temperature:k"},
+			{Name: "Pressure", UnitExternalId: "pressure:pa"},
+		}},
+		{Name: "Imperial", Quantities: []dto.UnitSystemQuantity{
+			{Name: "Temperature", UnitExternalId: "temperature:deg_f"},
+		}},
+	}}
+}
+
+func TestUnits_ListSystems(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/v1/projects/test-project/units/systems" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(testUnitSystems())
+	})
+
+	systems, err := client.Units.ListSystems()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(systems.Items) != 2 || systems.Items[0].Name != "SI" || len(systems.Items[0].Quantities) != 2 {
+		t.Errorf("Unexpected unit systems: %+v", systems)
+	}
+}
+
+func TestUnits_Retrieve(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/projects/test-project/units/byids" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"items":[{"externalId":"pressure:bar"},{"externalId":"temperature:k"}]}`
+		if string(body) != expected {
+			t.Errorf("Expected body %s, got %s", expected, body)
+		}
+		_ = json.NewEncoder(w).Encode(dto.UnitList{Items: []dto.Unit{
+			{ExternalId: "pressure:bar", Quantity: "Pressure"},
+			{ExternalId: "temperature:k", Quantity: "Temperature"},
+		}})
+	})
+
+	units, err := client.Units.Retrieve([]string{"pressure:bar", "temperature:k"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(units.Items) != 2 || units.Items[1].ExternalId != "temperature:k" {
+		t.Errorf("Unexpected units: %+v", units)
+	}
+}
@@ -1,9 +1,16 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"reflect"
 	"strings"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/cdftime"
 )
 
 func buildQueryParams(params map[string]interface{}) string {
@@ -22,3 +29,60 @@ func buildQueryParams(params map[string]interface{}) string {
 	}
 	return strings.Join(queryParams, "&")
 }
+
+// doJSONRequest sends body (if any) as JSON to the given project endpoint and
+// decodes the JSON response into out (if any). Non-OK responses are returned
+// as *APIError.
+func doJSONRequest(client *CogniteClient, method string, endpoint string, body interface{}, out interface{}) error {
+	url := client.BaseURL + endpoint
+
+	var requestBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewBuffer(jsonBody)
+	}
+
+	req, err := http.NewRequest(method, url, requestBody)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range client.Headers {
+		req.Header.Set(key, value)
+	}
+
+	httpClient := &http.Client{}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp, responseBody)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(responseBody, out)
+}
+
+// resolveTimestamp converts epoch milliseconds, time.Time values and CDF time
+// expressions ("now", "2d-ago", "1h-ahead" or epoch milliseconds) to epoch
+// milliseconds relative to now.
+func resolveTimestamp(value interface{}, now time.Time) (int64, error) {
+	t, err := cdftime.ParseValue(value, now)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixMilli(), nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildQueryParams(t *testing.T) {
@@ -116,3 +117,42 @@ func BenchmarkBuildQueryParams(b *testing.B) {
 		_ = buildQueryParams(params)
 	}
 }
+
+func TestResolveTimestamp(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected time.Time
+		wantErr  bool
+	}{
+		{name: "Now", value: "now", expected: now},
+		{name: "Days ago", value: "2d-ago", expected: now.AddDate(0, 0, -2)},
+		{name: "Weeks ago", value: "1w-ago", expected: now.AddDate(0, 0, -7)},
+		{name: "Minutes ahead", value: "30m-ahead", expected: now.Add(30 * time.Minute)},
+		{name: "Epoch string", value: "1704067200000", expected: time.UnixMilli(1704067200000)},
+		{name: "Epoch int64", value: int64(1704067200000), expected: time.UnixMilli(1704067200000)},
+		{name: "time.Time", value: now.Add(-time.Hour), expected: now.Add(-time.Hour)},
+		{name: "Invalid unit", value: "2y-ago", wantErr: true},
+		{name: "Unsupported type", value: 1.5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := resolveTimestamp(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %v", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected.UnixMilli() {
+				t.Errorf("resolveTimestamp(%v) = %d, want %d", tt.value, result, tt.expected.UnixMilli())
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// viewsItemsLimit is the maximum number of views per apply, byids or delete
+// request.
+const viewsItemsLimit = 100
+
+// List lists one page of views, optionally in a single space. With
+// includeInheritedProperties the properties of the views each view
+// implements are included in its Properties. Pass the NextCursor of the
+// previous page as cursor to get the next one.
+func (v *Views) List(
+	limit int,
+	cursor *string,
+	space *string,
+	includeInheritedProperties bool,
+	allVersions bool,
+	includeGlobal bool,
+) (dto.ViewList, error) {
+	queryParams := map[string]interface{}{
+		"limit":                      limit,
+		"includeInheritedProperties": includeInheritedProperties,
+		"allVersions":                allVersions,
+		"includeGlobal":              includeGlobal,
+	}
+	if cursor != nil {
+		queryParams["cursor"] = url.QueryEscape(*cursor)
+	}
+	if space != nil {
+		queryParams["space"] = url.QueryEscape(*space)
+	}
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/views?%s", v.Client.ClientConfig.Project, buildQueryParams(queryParams))
+
+	var viewList dto.ViewList
+	if err := doJSONRequest(v.Client, "GET", endpoint, nil, &viewList); err != nil {
+		return dto.ViewList{}, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	return viewList, nil
+}
+
+// ListAll lists every view, optionally in a single space, following the
+// cursors.
+func (v *Views) ListAll(space *string, includeInheritedProperties bool, allVersions bool, includeGlobal bool) (dto.ViewList, error) {
+	var all dto.ViewList
+	var cursor *string
+	for {
+		page, err := v.List(1000, cursor, space, includeInheritedProperties, allVersions, includeGlobal)
+		if err != nil {
+			return all, err
+		}
+		all.Items = append(all.Items, page.Items...)
+		if page.NextCursor == nil || *page.NextCursor == "" {
+			return all, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// Retrieve fetches views by space, external ID and version. With
+// includeInheritedProperties each view carries its effective property set,
+// including the properties of the views it implements. Unknown views are
+// left out of the result.
+func (v *Views) Retrieve(ids []dto.ViewId, includeInheritedProperties bool) (dto.ViewList, error) {
+	queryParams := map[string]interface{}{
+		"includeInheritedProperties": includeInheritedProperties,
+	}
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/views/byids?%s", v.Client.ClientConfig.Project, buildQueryParams(queryParams))
+
+	var retrieved dto.ViewList
+	for start := 0; start < len(ids); start += viewsItemsLimit {
+		end := min(start+viewsItemsLimit, len(ids))
+
+		var viewList dto.ViewList
+		body := map[string]interface{}{"items": ids[start:end]}
+		if err := doJSONRequest(v.Client, "POST", endpoint, body, &viewList); err != nil {
+			return retrieved, fmt.Errorf("failed to retrieve views: %w", err)
+		}
+		retrieved.Items = append(retrieved.Items, viewList.Items...)
+	}
+
+	return retrieved, nil
+}
+
+// Apply creates the view versions that do not exist and updates the others.
+func (v *Views) Apply(items []dto.ViewApply) (dto.ViewList, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/views", v.Client.ClientConfig.Project)
+
+	var applied dto.ViewList
+	for start := 0; start < len(items); start += viewsItemsLimit {
+		end := min(start+viewsItemsLimit, len(items))
+
+		var viewList dto.ViewList
+		body := map[string]interface{}{"items": items[start:end]}
+		if err := doJSONRequest(v.Client, "POST", endpoint, body, &viewList); err != nil {
+			return applied, fmt.Errorf("failed to apply views: %w", err)
+		}
+		applied.Items = append(applied.Items, viewList.Items...)
+	}
+
+	return applied, nil
+}
+
+// Delete deletes view versions and returns the views CDF deleted. The data
+// stays in the containers the views map.
+func (v *Views) Delete(ids []dto.ViewId) ([]dto.ViewId, error) {
+	endpoint := fmt.Sprintf("/api/v1/projects/%s/models/views/delete", v.Client.ClientConfig.Project)
+
+	var deleted []dto.ViewId
+	for start := 0; start < len(ids); start += viewsItemsLimit {
+		end := min(start+viewsItemsLimit, len(ids))
+
+		var response struct {
+			Items []dto.ViewId `json:"items"`
+		}
+		body := map[string]interface{}{"items": ids[start:end]}
+		if err := doJSONRequest(v.Client, "POST", endpoint, body, &response); err != nil {
+			return deleted, fmt.Errorf("failed to delete views: %w", err)
+		}
+		deleted = append(deleted, response.Items...)
+	}
+
+	return deleted, nil
+}
+
+// Implements retrieves the views a view implements, directly or through
+// other views, nearest first. Each view is returned once, even when it is
+// reached along several paths, and a view that cannot be found is an error.
+func (v *Views) Implements(id dto.ViewId) ([]dto.View, error) {
+	seen := map[dto.ViewId]bool{id: true}
+	var chain []dto.View
+
+	level := []dto.ViewId{id}
+	for len(level) > 0 {
+		views, err := v.Retrieve(level, false)
+		if err != nil {
+			return chain, err
+		}
+		byId := make(map[dto.ViewId]dto.View, len(views.Items))
+		for _, view := range views.Items {
+			byId[view.Id()] = view
+		}
+
+		var next []dto.ViewId
+		for _, levelId := range level {
+			view, ok := byId[levelId]
+			if !ok {
+				return chain, fmt.Errorf("view %s:%s/%s not found", levelId.Space, levelId.ExternalId, levelId.Version)
+			}
+			if levelId != id {
+				chain = append(chain, view)
+			}
+			for _, parent := range view.Implements {
+				parentId := dto.ViewId{Space: parent.Space, ExternalId: parent.ExternalId, Version: parent.Version}
+				if !seen[parentId] {
+					seen[parentId] = true
+					next = append(next, parentId)
+				}
+			}
+		}
+		level = next
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+const testTimeSeriesView = `{"space":"cdf_cdm","externalId":"CogniteTimeSeries","version":"v1","writable":true,"usedFor":"node","isGlobal":true,
+	"implements":[{"type":"view","space":"cdf_cdm","externalId":"CogniteDescribable","version":"v1"}],
+	"properties":{
+		"name":{"container":{"type":"container","space":"cdf_cdm","externalId":"CogniteDescribable"},"containerPropertyIdentifier":"name",
+			"type":{"type":"text","list":false,"collation":"ucs_basic"},"nullable":true,"immutable":false,"autoIncrement":false},
+		"unit":{"container":{"type":"container","space":"cdf_cdm","externalId":"CogniteTimeSeries"},"containerPropertyIdentifier":"unit",
+			"type":{"type":"direct","list":false},"nullable":true,"source":{"type":"view","space":"cdf_cdm","externalId":"CogniteUnit","version":"v1"}},
+		"activities":{"connectionType":"multi_reverse_direct_relation","source":{"type":"view","space":"cdf_cdm","externalId":"CogniteActivity","version":"v1"},
+			"through":{"source":{"type":"view","space":"cdf_cdm","externalId":"CogniteActivity","version":"v1"},"identifier":"timeSeries"}},
+		"pumps":{"connectionType":"multi_edge_connection","type":{"space":"site-oslo","externalId":"feeds"},"direction":"inwards",
+			"source":{"type":"view","space":"site-oslo","externalId":"Pump","version":"v1"}}
+	}}`
+
+func TestViews_Retrieve(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/projects/test-project/models/views/byids" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.RawQuery != "includeInheritedProperties=true" {
+			t.Errorf("Expected includeInheritedProperties=true, got %s", r.URL.RawQuery)
+		}
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"items":[{"space":"cdf_cdm","externalId":"CogniteTimeSeries","version":"v1"}]}`
+		if string(body) != expected {
+			t.Errorf("Expected body %s, got %s", expected, body)
+		}
+		_, _ = w.Write([]byte(`{"items":[` + testTimeSeriesView + `]}`))
+	})
+
+	views, err := client.DataModeling.Views.Retrieve([]dto.ViewId{{Space: "cdf_cdm", ExternalId: "CogniteTimeSeries", Version: "v1"}}, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(views.Items) != 1 {
+		t.Fatalf("Expected 1 view, got %d", len(views.Items))
+	}
+	view := views.Items[0]
+	if len(view.Implements) != 1 || view.Implements[0].ExternalId != "CogniteDescribable" {
+		t.Errorf("Unexpected implements: %+v", view.Implements)
+	}
+
+	name := view.Properties["name"]
+	if name.IsConnection() || name.Type == nil || name.Type.Type != dto.PropertyTypeText || name.Type.Collation != "ucs_basic" ||
+		name.Container.ExternalId != "CogniteDescribable" || name.Nullable == nil || !*name.Nullable {
+		t.Errorf("Unexpected name property: %+v", name)
+	}
+	unit := view.Properties["unit"]
+	if unit.Type == nil || unit.Type.Type != dto.PropertyTypeDirect || unit.Source == nil || unit.Source.ExternalId != "CogniteUnit" {
+		t.Errorf("Unexpected unit property: %+v", unit)
+	}
+	activities := view.Properties["activities"]
+	if !activities.IsConnection() || activities.IsEdge() || activities.Type != nil ||
+		activities.Through == nil || activities.Through.Identifier != "timeSeries" || activities.Through.Source.Type != "view" {
+		t.Errorf("Unexpected activities property: %+v", activities)
+	}
+	pumps := view.Properties["pumps"]
+	if !pumps.IsEdge() || pumps.Type != nil || pumps.EdgeType == nil || pumps.EdgeType.ExternalId != "feeds" ||
+		pumps.Direction != dto.EdgeDirectionInwards {
+		t.Errorf("Unexpected pumps property: %+v", pumps)
+	}
+}
+
+func TestViewProperty_roundTrip(t *testing.T) {
+	var view dto.View
+	if err := json.Unmarshal([]byte(testTimeSeriesView), &view); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := json.Marshal(view)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded dto.View
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if decoded.Properties["pumps"].EdgeType == nil || decoded.Properties["pumps"].EdgeType.ExternalId != "feeds" {
+		t.Errorf("Expected the edge type to survive a round trip, got %+v", decoded.Properties["pumps"])
+	}
+	if decoded.Properties["name"].Type == nil || decoded.Properties["name"].Type.Type != dto.PropertyTypeText {
+		t.Errorf("Expected the property type to survive a round trip, got %+v", decoded.Properties["name"])
+	}
+	if nullable := decoded.Properties["name"].Nullable; nullable == nil || !*nullable {
+		t.Errorf("Expected nullable to survive a round trip, got %v", nullable)
+	}
+	if decoded.Properties["activities"].Nullable != nil {
+		t.Errorf("Expected nullable to stay unset on connections, got %v", *decoded.Properties["activities"].Nullable)
+	}
+}
+
+func TestViews_Apply(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"items":[{"space":"site-oslo","externalId":"Pump","version":"v1",` +
+			`"implements":[{"type":"view","space":"cdf_cdm","externalId":"CogniteAsset","version":"v1"}],` +
+			`"properties":{"feeds":{"source":{"type":"view","space":"site-oslo","externalId":"Pump","version":"v1"},` +
+			`"connectionType":"multi_edge_connection","type":{"space":"site-oslo","externalId":"feeds"},"direction":"outwards"},` +
+			`"flow":{"container":{"type":"container","space":"site-oslo","externalId":"Pump"},"containerPropertyIdentifier":"flow"}}}]}`
+		if string(body) != expected {
+			t.Errorf("Expected body:\n%s\ngot:\n%s", expected, body)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"space":"site-oslo","externalId":"Pump","version":"v1","properties":{}}]}`))
+	})
+
+	pump := dto.ViewReference{Type: "view", Space: "site-oslo", ExternalId: "Pump", Version: "v1"}
+	applied, err := client.DataModeling.Views.Apply([]dto.ViewApply{{
+		Space:      "site-oslo",
+		ExternalId: "Pump",
+		Version:    "v1",
+		Implements: []dto.ViewReference{{Type: "view", Space: "cdf_cdm", ExternalId: "CogniteAsset", Version: "v1"}},
+		Properties: map[string]dto.ViewPropertyApply{
+			"flow": {
+				Container:                   &dto.ContainerReference{Type: "container", Space: "site-oslo", ExternalId: "Pump"},
+				ContainerPropertyIdentifier: "flow",
+			},
+			"feeds": {
+				ConnectionType: dto.ConnectionTypeMultiEdge,
+				Type:           &dto.DirectRelationReference{Space: "site-oslo", ExternalId: "feeds"},
+				Source:         &pump,
+				Direction:      dto.EdgeDirectionOutwards,
+			},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(applied.Items) != 1 || applied.Items[0].Id() != (dto.ViewId{Space: "site-oslo", ExternalId: "Pump", Version: "v1"}) {
+		t.Errorf("Unexpected applied views: %+v", applied.Items)
+	}
+}
+
+func TestViews_ListAndDelete(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/projects/test-project/models/views":
+			for _, param := range []string{"space=cdf_cdm", "includeInheritedProperties=true", "allVersions=false", "includeGlobal=true"} {
+				if !strings.Contains(r.URL.RawQuery, param) {
+					t.Errorf("Expected %s in query %s", param, r.URL.RawQuery)
+				}
+			}
+			_, _ = w.Write([]byte(`{"items":[` + testTimeSeriesView + `]}`))
+		case "/api/v1/projects/test-project/models/views/delete":
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"items":[{"space":"site-oslo","externalId":"Pump","version":"v1"}]}` {
+				t.Errorf("Unexpected body %s", body)
+			}
+			_, _ = w.Write(body)
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	views := client.DataModeling.Views
+
+	space := "cdf_cdm"
+	list, err := views.ListAll(&space, true, false, true)
+	if err != nil || len(list.Items) != 1 || list.Items[0].ExternalId != "CogniteTimeSeries" {
+		t.Errorf("Expected the CogniteTimeSeries view, got %+v, %v", list.Items, err)
+	}
+
+	id := dto.ViewId{Space: "site-oslo", ExternalId: "Pump", Version: "v1"}
+	deleted, err := views.Delete([]dto.ViewId{id})
+	if err != nil || len(deleted) != 1 || deleted[0] != id {
+		t.Errorf("Expected the Pump view to be deleted, got %v, %v", deleted, err)
+	}
+}
+
+func TestViews_Implements(t *testing.T) {
+	ref := func(externalId string) dto.ViewReference {
+		return dto.ViewReference{Type: "view", Space: "cdf_cdm", ExternalId: externalId, Version: "v1"}
+	}
+	// CogniteTimeSeries implements Describable and Sourceable, which both
+	// implement Base.
+	all := map[string]dto.View{
+		"CogniteTimeSeries": {Implements: []dto.ViewReference{ref("Describable"), ref("Sourceable")}},
+		"Describable":       {Implements: []dto.ViewReference{ref("Base")}},
+		"Sourceable":        {Implements: []dto.ViewReference{ref("Base")}},
+		"Base":              {},
+	}
+	var requests int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var body struct {
+			Items []dto.ViewId `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+		var response dto.ViewList
+		for _, id := range body.Items {
+			if view, ok := all[id.ExternalId]; ok {
+				view.Space, view.ExternalId, view.Version = id.Space, id.ExternalId, id.Version
+				response.Items = append(response.Items, view)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	})
+
+	chain, err := client.DataModeling.Views.Implements(dto.ViewId{Space: "cdf_cdm", ExternalId: "CogniteTimeSeries", Version: "v1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, view := range chain {
+		names = append(names, view.ExternalId)
+	}
+	if strings.Join(names, ",") != "Describable,Sourceable,Base" {
+		t.Errorf("Expected Describable,Sourceable,Base, got %v", names)
+	}
+	if requests != 3 {
+		t.Errorf("Expected one request per level, got %d", requests)
+	}
+
+	delete(all, "Base")
+	_, err = client.DataModeling.Views.Implements(dto.ViewId{Space: "cdf_cdm", ExternalId: "CogniteTimeSeries", Version: "v1"})
+	if err == nil || !strings.Contains(err.Error(), "cdf_cdm:Base/v1 not found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Package cdftime parses and formats the time expressions and aggregate
+// granularities accepted by the CDF time series API.
+package cdftime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var relativeTimePattern = regexp.MustCompile(`^(\d+)(w|d|h|m|s)-(ago|ahead)$`)
+
+var relativeTimeUnits = map[string]time.Duration{
+	"w": 7 * 24 * time.Hour,
+	"d": 24 * time.Hour,
+	"h": time.Hour,
+	"m": time.Minute,
+	"s": time.Second,
+}
+
+// relativeTimeOrder lists the relative units from longest to shortest.
+var relativeTimeOrder = []string{"w", "d", "h", "m", "s"}
+
+// Parse parses a CDF time expression: "now", a relative time such as
+// "2d-ago" or "30m-ahead", or epoch milliseconds. Relative times are
+// resolved against now.
+func Parse(expr string, now time.Time) (time.Time, error) {
+	if expr == "now" {
+		return now, nil
+	}
+	if ms, err := strconv.ParseInt(expr, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	match := relativeTimePattern.FindStringSubmatch(expr)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", expr)
+	}
+	count, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %w", expr, err)
+	}
+	offset := time.Duration(count) * relativeTimeUnits[match[2]]
+	if offset/relativeTimeUnits[match[2]] != time.Duration(count) {
+		return time.Time{}, fmt.Errorf("invalid time %q: offset out of range", expr)
+	}
+	if match[3] == "ago" {
+		offset = -offset
+	}
+	return now.Add(offset), nil
+}
+
+// ParseValue parses the values accepted for start, end and before fields:
+// epoch milliseconds as int64 or int, a time.Time, or a CDF time expression.
+func ParseValue(value interface{}, now time.Time) (time.Time, error) {
+	switch v := value.(type) {
+	case int64:
+		return time.UnixMilli(v).UTC(), nil
+	case int:
+		return time.UnixMilli(int64(v)).UTC(), nil
+	case time.Time:
+		return v, nil
+	case string:
+		return Parse(v, now)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported time value %v of type %T", value, value)
+	}
+}
+
+// Validate reports whether expr is a valid CDF time expression.
+func Validate(expr string) error {
+	_, err := Parse(expr, time.Now())
+	return err
+}
+
+// Format formats t as epoch milliseconds.
+func Format(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// FormatRelative formats t relative to now, using the longest unit that
+// represents the offset exactly, e.g. "2d-ago" or "90m-ahead". Offsets that
+// are not whole seconds are formatted as epoch milliseconds.
+func FormatRelative(t time.Time, now time.Time) string {
+	offset := now.Sub(t).Truncate(time.Millisecond)
+	if offset == 0 {
+		return "now"
+	}
+	direction := "ago"
+	if offset < 0 {
+		offset = -offset
+		direction = "ahead"
+	}
+	for _, unit := range relativeTimeOrder {
+		if offset%relativeTimeUnits[unit] == 0 {
+			return fmt.Sprintf("%d%s-%s", offset/relativeTimeUnits[unit], unit, direction)
+		}
+	}
+	return Format(t)
+}
@@ -0,0 +1,111 @@
+package cdftime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected time.Time
+		wantErr  bool
+	}{
+		{name: "Now", expr: "now", expected: now},
+		{name: "Weeks ago", expr: "1w-ago", expected: now.AddDate(0, 0, -7)},
+		{name: "Days ago", expr: "300d-ago", expected: now.AddDate(0, 0, -300)},
+		{name: "Hours ago", expr: "6h-ago", expected: now.Add(-6 * time.Hour)},
+		{name: "Minutes ahead", expr: "30m-ahead", expected: now.Add(30 * time.Minute)},
+		{name: "Seconds ago", expr: "45s-ago", expected: now.Add(-45 * time.Second)},
+		{name: "Epoch", expr: "1704067200000", expected: time.UnixMilli(1704067200000)},
+		{name: "Unknown unit", expr: "2y-ago", wantErr: true},
+		{name: "Missing direction", expr: "2d", wantErr: true},
+		{name: "Empty", expr: "", wantErr: true},
+		{name: "Overflow", expr: "99999999999999w-ago", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.expr, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.expr, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected time.Time
+		wantErr  bool
+	}{
+		{name: "int64", value: int64(1704067200000), expected: time.UnixMilli(1704067200000)},
+		{name: "int", value: 1000, expected: time.UnixMilli(1000)},
+		{name: "time.Time", value: now.Add(-time.Hour), expected: now.Add(-time.Hour)},
+		{name: "Expression", value: "2d-ago", expected: now.AddDate(0, 0, -2)},
+		{name: "Unsupported type", value: 1.5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseValue(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %v", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("ParseValue(%v) = %v, want %v", tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatRelative(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		time     time.Time
+		expected string
+	}{
+		{now, "now"},
+		{now.AddDate(0, 0, -14), "2w-ago"},
+		{now.AddDate(0, 0, -3), "3d-ago"},
+		{now.Add(90 * time.Minute), "90m-ahead"},
+		{now.Add(-1500 * time.Millisecond), "1704887998500"},
+	}
+
+	for _, tt := range tests {
+		result := FormatRelative(tt.time, now)
+		if result != tt.expected {
+			t.Errorf("FormatRelative(%v) = %q, want %q", tt.time, result, tt.expected)
+		}
+		if parsed, err := Parse(result, now); err != nil || !parsed.Equal(tt.time) {
+			t.Errorf("Parse(%q) = %v, %v; want %v", result, parsed, err, tt.time)
+		}
+	}
+
+	if Format(now) != "1704888000000" {
+		t.Errorf("Unexpected Format result %s", Format(now))
+	}
+}
@@ -0,0 +1,186 @@
+package cdftime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Unit is a granularity unit.
+type Unit string
+
+const (
+	Second  Unit = "s"
+	Minute  Unit = "m"
+	Hour    Unit = "h"
+	Day     Unit = "d"
+	Week    Unit = "w"
+	Month   Unit = "mo"
+	Quarter Unit = "q"
+	Year    Unit = "y"
+)
+
+var granularityPattern = regexp.MustCompile(`^(\d*)([a-z]+)$`)
+
+// unitNames maps the unit abbreviations to their unit. Spelled out units
+// such as "1hour" are rejected.
+var unitNames = map[string]Unit{
+	"s":  Second,
+	"m":  Minute,
+	"h":  Hour,
+	"d":  Day,
+	"w":  Week,
+	"mo": Month,
+	"q":  Quarter,
+	"y":  Year,
+}
+
+// maxGranularity holds CDF's maximum count for the units that have one.
+var maxGranularity = map[Unit]int{
+	Second: 120,
+	Minute: 120,
+	Hour:   100000,
+	Day:    100000,
+}
+
+// Granularity is an aggregate granularity such as "1h" or "15m".
+type Granularity struct {
+	Count int
+	Unit  Unit
+}
+
+// ParseGranularity parses and validates a granularity. The count defaults
+// to 1 when omitted, as in "h".
+func ParseGranularity(s string) (Granularity, error) {
+	match := granularityPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Granularity{}, fmt.Errorf("invalid granularity %q", s)
+	}
+	unit, ok := unitNames[match[2]]
+	if !ok {
+		return Granularity{}, fmt.Errorf("invalid granularity %q: unknown unit %q", s, match[2])
+	}
+	count := 1
+	if match[1] != "" {
+		var err error
+		if count, err = strconv.Atoi(match[1]); err != nil {
+			return Granularity{}, fmt.Errorf("invalid granularity %q: %w", s, err)
+		}
+	}
+	g := Granularity{Count: count, Unit: unit}
+	if err := g.Validate(); err != nil {
+		return Granularity{}, err
+	}
+	return g, nil
+}
+
+// Validate checks the count against CDF's limits for the unit.
+func (g Granularity) Validate() error {
+	if unit, ok := unitNames[string(g.Unit)]; !ok || unit != g.Unit {
+		return fmt.Errorf("invalid granularity %s: unknown unit %q", g, g.Unit)
+	}
+	if g.Count < 1 {
+		return fmt.Errorf("invalid granularity %s: count must be at least 1", g)
+	}
+	if limit, ok := maxGranularity[g.Unit]; ok && g.Count > limit {
+		return fmt.Errorf("invalid granularity %s: count must be at most %d for unit %s", g, limit, g.Unit)
+	}
+	return nil
+}
+
+func (g Granularity) String() string {
+	return strconv.Itoa(g.Count) + string(g.Unit)
+}
+
+// Duration returns the length of the granularity. It is only fixed for
+// units up to weeks; calendar units return false.
+func (g Granularity) Duration() (time.Duration, bool) {
+	switch g.Unit {
+	case Second:
+		return time.Duration(g.Count) * time.Second, true
+	case Minute:
+		return time.Duration(g.Count) * time.Minute, true
+	case Hour:
+		return time.Duration(g.Count) * time.Hour, true
+	case Day:
+		return time.Duration(g.Count) * 24 * time.Hour, true
+	case Week:
+		return time.Duration(g.Count) * 7 * 24 * time.Hour, true
+	default:
+		return 0, false
+	}
+}
+
+// Align rounds t down to a whole unit in loc: the start of the second,
+// minute, hour or day, Monday for weeks, and the first day of the month,
+// quarter or year. Like CDF, it aligns to the unit rather than to multiples
+// of the count. A nil loc means UTC.
+func (g Granularity) Align(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	year, month, day := t.Date()
+	switch g.Unit {
+	case Second:
+		return t.Truncate(time.Second)
+	case Minute:
+		return time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, loc)
+	case Hour:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, loc)
+	case Day:
+		return time.Date(year, month, day, 0, 0, 0, 0, loc)
+	case Week:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, loc)
+	case Month:
+		return time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	case Quarter:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// Add adds n granularities to t. Days and longer units follow the calendar
+// of t's location, so a day across a daylight saving change is 23 or 25
+// hours long.
+func (g Granularity) Add(t time.Time, n int) time.Time {
+	count := g.Count * n
+	switch g.Unit {
+	case Second:
+		return t.Add(time.Duration(count) * time.Second)
+	case Minute:
+		return t.Add(time.Duration(count) * time.Minute)
+	case Hour:
+		return t.Add(time.Duration(count) * time.Hour)
+	case Day:
+		return t.AddDate(0, 0, count)
+	case Week:
+		return t.AddDate(0, 0, 7*count)
+	case Month:
+		return t.AddDate(0, count, 0)
+	case Quarter:
+		return t.AddDate(0, 3*count, 0)
+	default:
+		return t.AddDate(count, 0, 0)
+	}
+}
+
+// Boundaries returns the bucket starts covering [start, end), beginning at
+// start aligned in loc.
+func (g Granularity) Boundaries(start, end time.Time, loc *time.Location) []time.Time {
+	if g.Count < 1 {
+		return nil
+	}
+	var boundaries []time.Time
+	aligned := g.Align(start, loc)
+	for i := 0; ; i++ {
+		boundary := g.Add(aligned, i)
+		if !boundary.Before(end) {
+			return boundaries
+		}
+		boundaries = append(boundaries, boundary)
+	}
+}
@@ -0,0 +1,105 @@
+package cdftime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGranularity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Granularity
+		wantErr  bool
+	}{
+		{input: "1h", expected: Granularity{1, Hour}},
+		{input: "15m", expected: Granularity{15, Minute}},
+		{input: "d", expected: Granularity{1, Day}},
+		{input: "3mo", expected: Granularity{3, Month}},
+		{input: "2q", expected: Granularity{2, Quarter}},
+		{input: "1hour", wantErr: true},
+		{input: "120s", expected: Granularity{120, Second}},
+		{input: "121s", wantErr: true},
+		{input: "100001h", wantErr: true},
+		{input: "0d", wantErr: true},
+		{input: "1ms", wantErr: true},
+		{input: "h1", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseGranularity(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %v", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseGranularity(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGranularity_Align(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("Time zone database not available: %v", err)
+	}
+	// Thursday 2024-05-16 01:30 in Oslo (UTC+2), still 2024-05-15 in UTC
+	ts := time.Date(2024, 5, 15, 23, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		granularity string
+		loc         *time.Location
+		expected    time.Time
+	}{
+		{"15m", nil, time.Date(2024, 5, 15, 23, 30, 0, 0, time.UTC)},
+		{"1h", oslo, time.Date(2024, 5, 16, 1, 0, 0, 0, oslo)},
+		{"1d", nil, time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{"1d", oslo, time.Date(2024, 5, 16, 0, 0, 0, 0, oslo)},
+		{"1w", oslo, time.Date(2024, 5, 13, 0, 0, 0, 0, oslo)},
+		{"1mo", oslo, time.Date(2024, 5, 1, 0, 0, 0, 0, oslo)},
+		{"1q", oslo, time.Date(2024, 4, 1, 0, 0, 0, 0, oslo)},
+		{"1y", nil, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		g, err := ParseGranularity(tt.granularity)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result := g.Align(ts, tt.loc); !result.Equal(tt.expected) {
+			t.Errorf("%s aligned in %v = %v, want %v", tt.granularity, tt.loc, result, tt.expected)
+		}
+	}
+}
+
+func TestGranularity_Boundaries(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("Time zone database not available: %v", err)
+	}
+	// Daylight saving starts in Oslo on 2024-03-31, so that day is 23 hours
+	start := time.Date(2024, 3, 30, 12, 0, 0, 0, oslo)
+	end := time.Date(2024, 4, 2, 0, 0, 0, 0, oslo)
+
+	boundaries := Granularity{1, Day}.Boundaries(start, end, oslo)
+
+	if len(boundaries) != 3 {
+		t.Fatalf("Expected 3 boundaries, got %v", boundaries)
+	}
+	if hours := boundaries[2].Sub(boundaries[1]).Hours(); hours != 23 {
+		t.Errorf("Expected a 23 hour day, got %v", hours)
+	}
+	if d, ok := (Granularity{2, Hour}).Duration(); !ok || d != 2*time.Hour {
+		t.Errorf("Unexpected duration %v", d)
+	}
+	if _, ok := (Granularity{1, Month}).Duration(); ok {
+		t.Error("Expected months to have no fixed duration")
+	}
+}
@@ -0,0 +1,150 @@
+package dimension
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// Unit is a unit as a dimension and its conversion to the coherent SI unit
+// of that dimension, value*Multiplier + Offset. ExternalId is set for units
+// taken from the catalog and empty for derived units.
+type Unit struct {
+	ExternalId string
+	Dimension  Dimension
+	Multiplier float64
+	Offset     float64
+}
+
+// Mul returns the product of two units, such as m³/h times h.
+func (u Unit) Mul(other Unit) (Unit, error) {
+	if err := checkNoOffset(u, other); err != nil {
+		return Unit{}, err
+	}
+	return Unit{Dimension: u.Dimension.Mul(other.Dimension), Multiplier: u.Multiplier * other.Multiplier}, nil
+}
+
+// Div returns the quotient of two units.
+func (u Unit) Div(other Unit) (Unit, error) {
+	if err := checkNoOffset(u, other); err != nil {
+		return Unit{}, err
+	}
+	return Unit{Dimension: u.Dimension.Div(other.Dimension), Multiplier: u.Multiplier / other.Multiplier}, nil
+}
+
+// Pow returns the unit raised to the power n.
+func (u Unit) Pow(n int) (Unit, error) {
+	if err := checkNoOffset(u); err != nil {
+		return Unit{}, err
+	}
+	return Unit{Dimension: u.Dimension.Pow(n), Multiplier: math.Pow(u.Multiplier, float64(n))}, nil
+}
+
+// Delta returns the unit of a difference between two values in u. It drops
+// the offset, so a difference of °C can be multiplied and divided.
+func (u Unit) Delta() Unit {
+	u.ExternalId = ""
+	u.Offset = 0
+	return u
+}
+
+// checkNoOffset rejects units with an offset, such as °C, whose products are
+// not defined. Use Delta for differences.
+func checkNoOffset(units ...Unit) error {
+	for _, u := range units {
+		if u.Offset != 0 {
+			return fmt.Errorf("unit %s has an offset and cannot be combined; use Delta for differences", u.ExternalId)
+		}
+	}
+	return nil
+}
+
+// Algebra assigns dimensions to the units of a unit catalog and finds the
+// catalog units of derived dimensions. It assumes the conversions of the
+// catalog map to coherent SI units, such as Pa for Pressure.
+type Algebra struct {
+	units        []dto.Unit
+	byExternalId map[string]int
+	quantities   map[string]Dimension
+}
+
+// NewAlgebra creates an algebra for a unit catalog, such as the result of
+// Units.List. quantities maps quantity names to dimensions and is added to
+// QuantityDimensions, overriding the quantities both have. It may be nil.
+func NewAlgebra(units dto.UnitList, quantities map[string]Dimension) *Algebra {
+	a := &Algebra{
+		units:        units.Items,
+		byExternalId: make(map[string]int, len(units.Items)),
+		quantities:   QuantityDimensions(),
+	}
+	maps.Copy(a.quantities, quantities)
+	for i := range units.Items {
+		a.byExternalId[units.Items[i].ExternalId] = i
+	}
+	return a
+}
+
+// Unit returns a catalog unit with its dimension.
+func (a *Algebra) Unit(externalId string) (Unit, error) {
+	index, ok := a.byExternalId[externalId]
+	if !ok {
+		return Unit{}, fmt.Errorf("unknown unit %q", externalId)
+	}
+	unit := &a.units[index]
+	dimension, ok := a.quantities[unit.Quantity]
+	if !ok {
+		return Unit{}, fmt.Errorf("unit %s has quantity %s with no known dimension", externalId, unit.Quantity)
+	}
+	return Unit{
+		ExternalId: externalId,
+		Dimension:  dimension,
+		Multiplier: unit.Conversion.Multiplier,
+		Offset:     unit.Conversion.Offset,
+	}, nil
+}
+
+// Quantities returns the quantities with the given dimension, sorted by
+// name.
+func (a *Algebra) Quantities(dimension Dimension) []string {
+	var quantities []string
+	for quantity, d := range a.quantities {
+		if d == dimension {
+			quantities = append(quantities, quantity)
+		}
+	}
+	slices.Sort(quantities)
+	return quantities
+}
+
+// Resolve returns the catalog units equal to u: same dimension, same
+// multiplier and no offset. Several units match when quantities share a
+// dimension, such as J and N·m. The error tells whether the dimension has
+// no quantity at all or only lacks a unit of that size.
+func (a *Algebra) Resolve(u Unit) ([]dto.Unit, error) {
+	quantities := a.Quantities(u.Dimension)
+	if len(quantities) == 0 {
+		return nil, fmt.Errorf("no quantity has dimension %s", u.Dimension)
+	}
+
+	var matches []dto.Unit
+	for _, unit := range a.units {
+		if slices.Contains(quantities, unit.Quantity) && unit.Conversion.Offset == 0 &&
+			sameMultiplier(unit.Conversion.Multiplier, u.Multiplier) {
+			matches = append(matches, unit)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no unit of %s has multiplier %g", strings.Join(quantities, " or "), u.Multiplier)
+	}
+	return matches, nil
+}
+
+// sameMultiplier compares multipliers with a relative tolerance, since
+// derived multipliers such as 1/3600*3600 are rarely exact.
+func sameMultiplier(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
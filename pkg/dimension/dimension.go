@@ -0,0 +1,125 @@
+// Package dimension does dimensional analysis on units of the CDF unit
+// catalog, so that the unit of a derived signal, such as flow times time or
+// voltage times current, can be found in the catalog.
+package dimension
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// Dimension holds the exponents of the SI base dimensions: length, mass,
+// time, electric current, temperature, amount of substance and luminous
+// intensity, in that order.
+type Dimension [7]int
+
+// The SI base dimensions.
+var (
+	Length      = Dimension{1, 0, 0, 0, 0, 0, 0}
+	Mass        = Dimension{0, 1, 0, 0, 0, 0, 0}
+	Time        = Dimension{0, 0, 1, 0, 0, 0, 0}
+	Current     = Dimension{0, 0, 0, 1, 0, 0, 0}
+	Temperature = Dimension{0, 0, 0, 0, 1, 0, 0}
+	Amount      = Dimension{0, 0, 0, 0, 0, 1, 0}
+	Luminosity  = Dimension{0, 0, 0, 0, 0, 0, 1}
+)
+
+// baseSymbols are the symbols of the base dimensions used by String.
+var baseSymbols = [7]string{"L", "M", "T", "I", "Θ", "N", "J"}
+
+// Mul returns the dimension of a product.
+func (d Dimension) Mul(other Dimension) Dimension {
+	for i := range d {
+		d[i] += other[i]
+	}
+	return d
+}
+
+// Div returns the dimension of a quotient.
+func (d Dimension) Div(other Dimension) Dimension {
+	for i := range d {
+		d[i] -= other[i]
+	}
+	return d
+}
+
+// Pow returns the dimension raised to the power n.
+func (d Dimension) Pow(n int) Dimension {
+	for i := range d {
+		d[i] *= n
+	}
+	return d
+}
+
+// IsDimensionless reports whether all exponents are zero.
+func (d Dimension) IsDimensionless() bool {
+	return d == Dimension{}
+}
+
+// String writes the dimension as base symbols with exponents, such as
+// "L2·M·T-3" for power, or "1" when dimensionless.
+func (d Dimension) String() string {
+	var parts []string
+	for i, exponent := range d {
+		switch exponent {
+		case 0:
+		case 1:
+			parts = append(parts, baseSymbols[i])
+		default:
+			parts = append(parts, baseSymbols[i]+strconv.Itoa(exponent))
+		}
+	}
+	if len(parts) == 0 {
+		return "1"
+	}
+	return strings.Join(parts, "·")
+}
+
+// quantityDimensions maps the quantities of the CDF unit catalog to their
+// dimension. Angles are dimensionless, so Frequency and Angular Velocity
+// share a dimension, as do Energy and Torque.
+var quantityDimensions = map[string]Dimension{
+	"Acceleration":           {1, 0, -2, 0, 0, 0, 0},
+	"Amount of Substance":    Amount,
+	"Angle":                  {},
+	"Angular Velocity":       {0, 0, -1, 0, 0, 0, 0},
+	"Area":                   {2, 0, 0, 0, 0, 0, 0},
+	"Capacitance":            {-2, -1, 4, 2, 0, 0, 0},
+	"Density":                {-3, 1, 0, 0, 0, 0, 0},
+	"Dimensionless":          {},
+	"Dynamic Viscosity":      {-1, 1, -1, 0, 0, 0, 0},
+	"Electric Charge":        {0, 0, 1, 1, 0, 0, 0},
+	"Electric Current":       Current,
+	"Electric Potential":     {2, 1, -3, -1, 0, 0, 0},
+	"Electric Resistance":    {2, 1, -3, -2, 0, 0, 0},
+	"Energy":                 {2, 1, -2, 0, 0, 0, 0},
+	"Force":                  {1, 1, -2, 0, 0, 0, 0},
+	"Frequency":              {0, 0, -1, 0, 0, 0, 0},
+	"Kinematic Viscosity":    {2, 0, -1, 0, 0, 0, 0},
+	"Length":                 Length,
+	"Luminous Intensity":     Luminosity,
+	"Mass":                   Mass,
+	"Mass Concentration":     {-3, 1, 0, 0, 0, 0, 0},
+	"Mass Flow Rate":         {0, 1, -1, 0, 0, 0, 0},
+	"Molar Concentration":    {-3, 0, 0, 0, 0, 1, 0},
+	"Molar Mass":             {0, 1, 0, 0, 0, -1, 0},
+	"Power":                  {2, 1, -3, 0, 0, 0, 0},
+	"Pressure":               {-1, 1, -2, 0, 0, 0, 0},
+	"Specific Energy":        {2, 0, -2, 0, 0, 0, 0},
+	"Specific Heat Capacity": {2, 0, -2, 0, -1, 0, 0},
+	"Temperature":            Temperature,
+	"Thermal Conductivity":   {1, 1, -3, 0, -1, 0, 0},
+	"Time":                   Time,
+	"Torque":                 {2, 1, -2, 0, 0, 0, 0},
+	"Velocity":               {1, 0, -1, 0, 0, 0, 0},
+	"Voltage":                {2, 1, -3, -1, 0, 0, 0},
+	"Volume":                 {3, 0, 0, 0, 0, 0, 0},
+	"Volume Flow Rate":       {3, 0, -1, 0, 0, 0, 0},
+}
+
+// QuantityDimensions returns a copy of the dimensions of the quantities of
+// the CDF unit catalog. Pass NewAlgebra the quantities it is missing.
+func QuantityDimensions() map[string]Dimension {
+	return maps.Clone(quantityDimensions)
+}
@@ -0,0 +1,146 @@
+package dimension
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestDimension_String(t *testing.T) {
+	tests := []struct {
+		dimension Dimension
+		expected  string
+	}{
+		{Dimension{}, "1"},
+		{Length, "L"},
+		{QuantityDimensions()["Power"], "L2·M·T-3"},
+		{Length.Pow(3).Div(Time), "L3·T-1"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.dimension.String(); result != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, result)
+		}
+	}
+}
+
+func testAlgebra() *Algebra {
+	return NewAlgebra(dto.UnitList{Items: []dto.Unit{
+		{ExternalId: "volume_flow_rate:m3-per-hr", Quantity: "Volume Flow Rate", Conversion: dto.UnitConversion{Multiplier: 1.0 / 3600}},
+		{ExternalId: "time:hr", Quantity: "Time", Conversion: dto.UnitConversion{Multiplier: 3600}},
+		{ExternalId: "time:sec", Quantity: "Time", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "volume:m3", Quantity: "Volume", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "electric_potential:v", Quantity: "Voltage", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "electric_current:a", Quantity: "Electric Current", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "power:w", Quantity: "Power", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "power:kilow", Quantity: "Power", Conversion: dto.UnitConversion{Multiplier: 1000}},
+		{ExternalId: "energy:kilow-hr", Quantity: "Energy", Conversion: dto.UnitConversion{Multiplier: 3.6e6}},
+		{ExternalId: "energy:j", Quantity: "Energy", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "torque:n-m", Quantity: "Torque", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "temperature:deg_c", Quantity: "Temperature", Conversion: dto.UnitConversion{Multiplier: 1, Offset: 273.15}},
+		{ExternalId: "temperature:k", Quantity: "Temperature", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "length:m", Quantity: "Length", Conversion: dto.UnitConversion{Multiplier: 1}},
+	}}, nil)
+}
+
+func TestAlgebra_Resolve(t *testing.T) {
+	algebra := testAlgebra()
+	unit := func(externalId string) Unit {
+		u, err := algebra.Unit(externalId)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return u
+	}
+	must := func(u Unit, err error) Unit {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return u
+	}
+
+	tests := []struct {
+		name     string
+		unit     Unit
+		expected []string
+	}{
+		{"flow times time", must(unit("volume_flow_rate:m3-per-hr").Mul(unit("time:hr"))), []string{"volume:m3"}},
+		{"voltage times current", must(unit("electric_potential:v").Mul(unit("electric_current:a"))), []string{"power:w"}},
+		{"kilowatt hours", must(unit("power:kilow").Mul(unit("time:hr"))), []string{"energy:kilow-hr"}},
+		// Energy and Torque share a dimension
+		{"watt seconds", must(unit("power:w").Mul(unit("time:sec"))), []string{"energy:j", "torque:n-m"}},
+		{"volume to length", must(unit("volume:m3").Div(must(unit("length:m").Pow(2)))), []string{"length:m"}},
+		{"temperature difference", unit("temperature:deg_c").Delta(), []string{"temperature:k"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			units, err := algebra.Resolve(tt.unit)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var externalIds []string
+			for _, u := range units {
+				externalIds = append(externalIds, u.ExternalId)
+			}
+			if strings.Join(externalIds, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, externalIds)
+			}
+		})
+	}
+}
+
+func TestAlgebra_errors(t *testing.T) {
+	algebra := testAlgebra()
+	celsius, _ := algebra.Unit("temperature:deg_c")
+	hour, _ := algebra.Unit("time:hr")
+	flow, _ := algebra.Unit("volume_flow_rate:m3-per-hr")
+
+	if _, err := celsius.Mul(hour); err == nil {
+		t.Error("Expected an error multiplying a unit with an offset")
+	}
+	if _, err := algebra.Unit("length:furlong"); err == nil {
+		t.Error("Expected an error for an unknown unit")
+	}
+
+	// m³/h per hour has no quantity
+	derived, _ := flow.Div(hour)
+	if _, err := algebra.Resolve(derived); err == nil || !strings.Contains(err.Error(), "no quantity has dimension L3·T-2") {
+		t.Errorf("Expected a missing quantity error, got %v", err)
+	}
+
+	// m³/s is a volume flow rate, but only m³/h is in the catalog
+	second, _ := algebra.Unit("time:sec")
+	volume, _ := algebra.Unit("volume:m3")
+	derived, _ = volume.Div(second)
+	if _, err := algebra.Resolve(derived); err == nil || !strings.Contains(err.Error(), "no unit of Volume Flow Rate") {
+		t.Errorf("Expected a missing unit error, got %v", err)
+	}
+}
+
+func TestAlgebra_quantities(t *testing.T) {
+	units := dto.UnitList{Items: []dto.Unit{
+		{ExternalId: "length:m", Quantity: "Length", Conversion: dto.UnitConversion{Multiplier: 1}},
+		{ExternalId: "jerk:m-per-sec3", Quantity: "Jerk", Conversion: dto.UnitConversion{Multiplier: 1}},
+	}}
+
+	// Changing the returned table does not change the defaults
+	defaults := QuantityDimensions()
+	delete(defaults, "Length")
+	if _, err := NewAlgebra(units, nil).Unit("length:m"); err != nil {
+		t.Errorf("Expected Length to keep its dimension, got %v", err)
+	}
+
+	if _, err := NewAlgebra(units, nil).Unit("jerk:m-per-sec3"); err == nil {
+		t.Error("Expected an error for a quantity with no known dimension")
+	}
+	algebra := NewAlgebra(units, map[string]Dimension{"Jerk": {1, 0, -3, 0, 0, 0, 0}})
+	jerk, err := algebra.Unit("jerk:m-per-sec3")
+	if err != nil || jerk.Dimension.String() != "L·T-3" {
+		t.Errorf("Expected the extra quantity to be used, got %+v, %v", jerk, err)
+	}
+	if _, err := algebra.Unit("length:m"); err != nil {
+		t.Errorf("Expected the default quantities to be kept, got %v", err)
+	}
+}
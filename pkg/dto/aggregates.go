@@ -0,0 +1,69 @@
+package dto
+
+import "github.com/evertoncolling/poc-requests-go/pkg/cdftime"
+
+// Aggregate names a datapoint aggregate. There is one for every field of
+// AggregateDatapoint.
+type Aggregate string
+
+// Names of the aggregates. They are untyped so that they fit both the
+// []string Aggregates of a DataPointsQueryItem and an Aggregate.
+const (
+	AggregateAverage            = "average"
+	AggregateMax                = "max"
+	AggregateMin                = "min"
+	AggregateCount              = "count"
+	AggregateSum                = "sum"
+	AggregateInterpolation      = "interpolation"
+	AggregateStepInterpolation  = "stepInterpolation"
+	AggregateContinuousVariance = "continuousVariance"
+	AggregateDiscreteVariance   = "discreteVariance"
+	AggregateTotalVariation     = "totalVariation"
+	AggregateCountGood          = "countGood"
+	AggregateCountUncertain     = "countUncertain"
+	AggregateCountBad           = "countBad"
+	AggregateDurationGood       = "durationGood"
+	AggregateDurationUncertain  = "durationUncertain"
+	AggregateDurationBad        = "durationBad"
+	AggregateMaxDatapoint       = "maxDatapoint"
+	AggregateMinDatapoint       = "minDatapoint"
+)
+
+// aggregateFields maps every aggregate to its AggregateDatapoint value. The
+// max and min datapoints yield the value of the datapoint.
+var aggregateFields = map[Aggregate]func(*AggregateDatapoint) float64{
+	AggregateAverage:            (*AggregateDatapoint).GetAverage,
+	AggregateMax:                (*AggregateDatapoint).GetMax,
+	AggregateMin:                (*AggregateDatapoint).GetMin,
+	AggregateCount:              (*AggregateDatapoint).GetCount,
+	AggregateSum:                (*AggregateDatapoint).GetSum,
+	AggregateInterpolation:      (*AggregateDatapoint).GetInterpolation,
+	AggregateStepInterpolation:  (*AggregateDatapoint).GetStepInterpolation,
+	AggregateContinuousVariance: (*AggregateDatapoint).GetContinuousVariance,
+	AggregateDiscreteVariance:   (*AggregateDatapoint).GetDiscreteVariance,
+	AggregateTotalVariation:     (*AggregateDatapoint).GetTotalVariation,
+	AggregateCountGood:          (*AggregateDatapoint).GetCountGood,
+	AggregateCountUncertain:     (*AggregateDatapoint).GetCountUncertain,
+	AggregateCountBad:           (*AggregateDatapoint).GetCountBad,
+	AggregateDurationGood:       (*AggregateDatapoint).GetDurationGood,
+	AggregateDurationUncertain:  (*AggregateDatapoint).GetDurationUncertain,
+	AggregateDurationBad:        (*AggregateDatapoint).GetDurationBad,
+	AggregateMaxDatapoint:       func(dp *AggregateDatapoint) float64 { return dp.GetMaxDatapoint().GetValue() },
+	AggregateMinDatapoint:       func(dp *AggregateDatapoint) float64 { return dp.GetMinDatapoint().GetValue() },
+}
+
+// Valid reports whether a is an aggregate CDF knows.
+func (a Aggregate) Valid() bool {
+	_, ok := aggregateFields[a]
+	return ok
+}
+
+// Granularity is an aggregate granularity such as "1h" or "15m".
+type Granularity string
+
+// Validate checks the granularity against CDF's rules, see
+// cdftime.ParseGranularity.
+func (g Granularity) Validate() error {
+	_, err := cdftime.ParseGranularity(string(g))
+	return err
+}
@@ -3,11 +3,49 @@ package dto
 type Metadata map[string]string
 
 type Identity struct {
-	Id         int64  `json:"id,omitempty"`
-	ExternalId string `json:"externalId,omitempty"`
+	Id         int64       `json:"id,omitempty"`
+	ExternalId string      `json:"externalId,omitempty"`
+	InstanceId *InstanceId `json:"instanceId,omitempty"`
 }
 
 type TimestampRange struct {
 	Min int64 `json:"min,omitempty"`
 	Max int64 `json:"max,omitempty"`
 }
+
+type ErrorResponse struct {
+	Error ErrorDetails `json:"error"`
+}
+
+type ErrorDetails struct {
+	Code       int        `json:"code"`
+	Message    string     `json:"message"`
+	Missing    []Identity `json:"missing,omitempty"`
+	Duplicated []Identity `json:"duplicated,omitempty"`
+}
+
+type StringPatch struct {
+	Set     *string `json:"set,omitempty"`
+	SetNull bool    `json:"setNull,omitempty"`
+}
+
+type Int64Patch struct {
+	Set     *int64 `json:"set,omitempty"`
+	SetNull bool   `json:"setNull,omitempty"`
+}
+
+type BoolPatch struct {
+	Set *bool `json:"set,omitempty"`
+}
+
+type MetadataPatch struct {
+	Set    *Metadata `json:"set,omitempty"`
+	Add    Metadata  `json:"add,omitempty"`
+	Remove []string  `json:"remove,omitempty"`
+}
+
+type Int64ListPatch struct {
+	Set    *[]int64 `json:"set,omitempty"`
+	Add    []int64  `json:"add,omitempty"`
+	Remove []int64  `json:"remove,omitempty"`
+}
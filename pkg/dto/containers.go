@@ -0,0 +1,138 @@
+package dto
+
+// Property types of container properties.
+const (
+	PropertyTypeText       = "text"
+	PropertyTypeBoolean    = "boolean"
+	PropertyTypeFloat32    = "float32"
+	PropertyTypeFloat64    = "float64"
+	PropertyTypeInt32      = "int32"
+	PropertyTypeInt64      = "int64"
+	PropertyTypeTimestamp  = "timestamp"
+	PropertyTypeDate       = "date"
+	PropertyTypeJSON       = "json"
+	PropertyTypeDirect     = "direct"
+	PropertyTypeTimeSeries = "timeseries"
+	PropertyTypeFile       = "file"
+	PropertyTypeSequence   = "sequence"
+	PropertyTypeEnum       = "enum"
+)
+
+// What a container can hold, for ContainerApply.UsedFor.
+const (
+	ContainerUsedForNode = "node"
+	ContainerUsedForEdge = "edge"
+	ContainerUsedForAll  = "all"
+)
+
+// Constraint and index types.
+const (
+	ConstraintTypeUniqueness = "uniqueness"
+	ConstraintTypeRequires   = "requires"
+	IndexTypeBTree           = "btree"
+	IndexTypeInverted        = "inverted"
+)
+
+// ContainerReference points at a container, as the target of a direct
+// relation or a requires constraint. Type is always "container".
+type ContainerReference struct {
+	Type       string `json:"type"`
+	Space      string `json:"space"`
+	ExternalId string `json:"externalId"`
+}
+
+// ContainerId identifies a container in byids and delete requests.
+type ContainerId struct {
+	Space      string `json:"space"`
+	ExternalId string `json:"externalId"`
+}
+
+// PropertyUnit is the unit of a float property, with the unit the values
+// were written in when it differs.
+type PropertyUnit struct {
+	ExternalId string `json:"externalId"`
+	SourceUnit string `json:"sourceUnit,omitempty"`
+}
+
+// EnumValue is one of the values of an enum property.
+type EnumValue struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PropertyType is the type of a container property. Type is one of the
+// PropertyType constants, and the other fields apply to some types only:
+// Collation to text, Container to direct, Unit to float32 and float64, and
+// Values and UnknownValue to enum. List and MaxListSize apply to all types
+// but enum.
+type PropertyType struct {
+	Type         string               `json:"type"`
+	List         bool                 `json:"list,omitempty"`
+	MaxListSize  int                  `json:"maxListSize,omitempty"`
+	Collation    string               `json:"collation,omitempty"`
+	Container    *ContainerReference  `json:"container,omitempty"`
+	Unit         *PropertyUnit        `json:"unit,omitempty"`
+	Values       map[string]EnumValue `json:"values,omitempty"`
+	UnknownValue string               `json:"unknownValue,omitempty"`
+}
+
+// ContainerPropertyDefinition defines a container property. Nullable is a
+// pointer because CDF treats a missing value as true.
+type ContainerPropertyDefinition struct {
+	Type          PropertyType `json:"type"`
+	Nullable      *bool        `json:"nullable,omitempty"`
+	Immutable     bool         `json:"immutable,omitempty"`
+	AutoIncrement bool         `json:"autoIncrement,omitempty"`
+	DefaultValue  interface{}  `json:"defaultValue,omitempty"`
+	Name          string       `json:"name,omitempty"`
+	Description   string       `json:"description,omitempty"`
+}
+
+// ContainerConstraint is a uniqueness constraint on Properties, or a
+// requires constraint on the container in Require.
+type ContainerConstraint struct {
+	ConstraintType string              `json:"constraintType"`
+	Properties     []string            `json:"properties,omitempty"`
+	BySpace        bool                `json:"bySpace,omitempty"`
+	Require        *ContainerReference `json:"require,omitempty"`
+}
+
+// ContainerIndex is a btree or inverted index on Properties. Cursorable and
+// BySpace apply to btree indexes only.
+type ContainerIndex struct {
+	IndexType  string   `json:"indexType"`
+	Properties []string `json:"properties"`
+	Cursorable bool     `json:"cursorable,omitempty"`
+	BySpace    bool     `json:"bySpace,omitempty"`
+}
+
+// ContainerApply creates or updates a container.
+type ContainerApply struct {
+	Space       string                                 `json:"space"`
+	ExternalId  string                                 `json:"externalId"`
+	Name        string                                 `json:"name,omitempty"`
+	Description string                                 `json:"description,omitempty"`
+	UsedFor     string                                 `json:"usedFor,omitempty"`
+	Properties  map[string]ContainerPropertyDefinition `json:"properties"`
+	Constraints map[string]ContainerConstraint         `json:"constraints,omitempty"`
+	Indexes     map[string]ContainerIndex              `json:"indexes,omitempty"`
+}
+
+type Container struct {
+	Space           string                                 `json:"space"`
+	ExternalId      string                                 `json:"externalId"`
+	Name            string                                 `json:"name,omitempty"`
+	Description     string                                 `json:"description,omitempty"`
+	UsedFor         string                                 `json:"usedFor"`
+	Properties      map[string]ContainerPropertyDefinition `json:"properties"`
+	Constraints     map[string]ContainerConstraint         `json:"constraints,omitempty"`
+	Indexes         map[string]ContainerIndex              `json:"indexes,omitempty"`
+	CreatedTime     int64                                  `json:"createdTime"`
+	LastUpdatedTime int64                                  `json:"lastUpdatedTime"`
+	IsGlobal        bool                                   `json:"isGlobal"`
+}
+
+type ContainerList struct {
+	Items      []Container `json:"items"`
+	NextCursor *string     `json:"nextCursor,omitempty"`
+}
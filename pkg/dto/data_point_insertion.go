@@ -0,0 +1,40 @@
+package dto
+
+// NewNumericInsertionItem builds an insertion item for numeric datapoints,
+// addressing the time series by the first of InstanceId, ExternalId or Id set
+// on id.
+func NewNumericInsertionItem(id Identity, datapoints []*NumericDatapoint) *DataPointInsertionItem {
+	return &DataPointInsertionItem{
+		TimeSeriesReference: insertionReference(id),
+		DatapointType: &DataPointInsertionItem_NumericDatapoints{
+			NumericDatapoints: &NumericDatapoints{Datapoints: datapoints},
+		},
+	}
+}
+
+// NewStringInsertionItem builds an insertion item for string datapoints,
+// addressing the time series by the first of InstanceId, ExternalId or Id set
+// on id.
+func NewStringInsertionItem(id Identity, datapoints []*StringDatapoint) *DataPointInsertionItem {
+	return &DataPointInsertionItem{
+		TimeSeriesReference: insertionReference(id),
+		DatapointType: &DataPointInsertionItem_StringDatapoints{
+			StringDatapoints: &StringDatapoints{Datapoints: datapoints},
+		},
+	}
+}
+
+func insertionReference(id Identity) isDataPointInsertionItem_TimeSeriesReference {
+	switch {
+	case id.InstanceId != nil:
+		return &DataPointInsertionItem_InstanceId{
+			InstanceId: &InstanceId{Space: id.InstanceId.Space, ExternalId: id.InstanceId.ExternalId},
+		}
+	case id.ExternalId != "":
+		return &DataPointInsertionItem_ExternalId{ExternalId: id.ExternalId}
+	case id.Id != 0:
+		return &DataPointInsertionItem_Id{Id: id.Id}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,143 @@
+package dto
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DatapointKind identifies which datapoint list a DataPointListItem holds.
+type DatapointKind int
+
+const (
+	DatapointKindNone DatapointKind = iota
+	DatapointKindNumeric
+	DatapointKindString
+	DatapointKindAggregate
+)
+
+func (k DatapointKind) String() string {
+	switch k {
+	case DatapointKindNumeric:
+		return "numeric"
+	case DatapointKindString:
+		return "string"
+	case DatapointKindAggregate:
+		return "aggregate"
+	default:
+		return "none"
+	}
+}
+
+// Kind reports which kind of datapoints the item holds.
+func (x *DataPointListItem) Kind() DatapointKind {
+	switch x.GetDatapointType().(type) {
+	case *DataPointListItem_NumericDatapoints:
+		return DatapointKindNumeric
+	case *DataPointListItem_StringDatapoints:
+		return DatapointKindString
+	case *DataPointListItem_AggregateDatapoints:
+		return DatapointKindAggregate
+	default:
+		return DatapointKindNone
+	}
+}
+
+// Numeric returns the numeric datapoints, or nil for other kinds.
+func (x *DataPointListItem) Numeric() []*NumericDatapoint {
+	return x.GetNumericDatapoints().GetDatapoints()
+}
+
+// Strings returns the string datapoints, or nil for other kinds.
+func (x *DataPointListItem) Strings() []*StringDatapoint {
+	return x.GetStringDatapoints().GetDatapoints()
+}
+
+// Aggregates returns the aggregate datapoints, or nil for other kinds.
+func (x *DataPointListItem) Aggregates() []*AggregateDatapoint {
+	return x.GetAggregateDatapoints().GetDatapoints()
+}
+
+// Len returns the number of datapoints, whatever their kind.
+func (x *DataPointListItem) Len() int {
+	return len(x.Numeric()) + len(x.Strings()) + len(x.Aggregates())
+}
+
+// Timestamps returns the timestamps in epoch milliseconds, whatever the kind.
+func (x *DataPointListItem) Timestamps() []int64 {
+	timestamps := make([]int64, 0, x.Len())
+	for _, dp := range x.Numeric() {
+		timestamps = append(timestamps, dp.GetTimestamp())
+	}
+	for _, dp := range x.Strings() {
+		timestamps = append(timestamps, dp.GetTimestamp())
+	}
+	for _, dp := range x.Aggregates() {
+		timestamps = append(timestamps, dp.GetTimestamp())
+	}
+	return timestamps
+}
+
+// Times returns the timestamps as UTC times.
+func (x *DataPointListItem) Times() []time.Time {
+	timestamps := x.Timestamps()
+	times := make([]time.Time, len(timestamps))
+	for i, ts := range timestamps {
+		times[i] = time.UnixMilli(ts).UTC()
+	}
+	return times
+}
+
+// Values returns the raw values of a numeric item, with NaN for null values.
+// It returns nil for string and aggregate items. Aggregate datapoints carry
+// one value per requested aggregate, so read them with AggregateValues.
+func (x *DataPointListItem) Values() []float64 {
+	datapoints := x.Numeric()
+	if datapoints == nil {
+		return nil
+	}
+	values := make([]float64, len(datapoints))
+	for i, dp := range datapoints {
+		if dp.GetNullValue() {
+			values[i] = math.NaN()
+			continue
+		}
+		values[i] = dp.GetValue()
+	}
+	return values
+}
+
+// AggregateValues returns the values of one aggregate, such as
+// AggregateAverage or AggregateCountGood, for every aggregate datapoint. It
+// is the aggregate counterpart of Values, and fails for numeric and string
+// items.
+func (x *DataPointListItem) AggregateValues(aggregate Aggregate) ([]float64, error) {
+	field, ok := aggregateFields[aggregate]
+	if !ok {
+		return nil, fmt.Errorf("unknown aggregate %q", aggregate)
+	}
+	if kind := x.Kind(); kind == DatapointKindNumeric || kind == DatapointKindString {
+		return nil, fmt.Errorf("cannot read aggregate %q from %s datapoints", aggregate, kind)
+	}
+	datapoints := x.Aggregates()
+	values := make([]float64, len(datapoints))
+	for i, dp := range datapoints {
+		values[i] = field(dp)
+	}
+	return values, nil
+}
+
+// Time returns the timestamp as a UTC time.
+func (x *NumericDatapoint) Time() time.Time {
+	return time.UnixMilli(x.GetTimestamp()).UTC()
+}
+
+// Time returns the timestamp as a UTC time.
+func (x *StringDatapoint) Time() time.Time {
+	return time.UnixMilli(x.GetTimestamp()).UTC()
+}
+
+// Time returns the timestamp as a UTC time.
+func (x *AggregateDatapoint) Time() time.Time {
+	return time.UnixMilli(x.GetTimestamp()).UTC()
+}
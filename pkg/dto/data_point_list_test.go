@@ -0,0 +1,148 @@
+package dto
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDataPointListItem_accessors(t *testing.T) {
+	numeric := &DataPointListItem{DatapointType: &DataPointListItem_NumericDatapoints{
+		NumericDatapoints: &NumericDatapoints{Datapoints: []*NumericDatapoint{
+			{Timestamp: 1000, Value: 1.5},
+			{Timestamp: 2000, NullValue: true},
+		}},
+	}}
+	strings := &DataPointListItem{DatapointType: &DataPointListItem_StringDatapoints{
+		StringDatapoints: &StringDatapoints{Datapoints: []*StringDatapoint{
+			{Timestamp: 3000, Value: "OPEN"},
+		}},
+	}}
+	aggregates := &DataPointListItem{DatapointType: &DataPointListItem_AggregateDatapoints{
+		AggregateDatapoints: &AggregateDatapoints{Datapoints: []*AggregateDatapoint{
+			{Timestamp: 0, Average: 2, Count: 10},
+			{Timestamp: 3600000, Average: 4, Count: 5},
+		}},
+	}}
+	empty := &DataPointListItem{}
+
+	tests := []struct {
+		name       string
+		item       *DataPointListItem
+		kind       DatapointKind
+		timestamps []int64
+		values     []float64
+	}{
+		{"Numeric", numeric, DatapointKindNumeric, []int64{1000, 2000}, []float64{1.5, math.NaN()}},
+		{"String", strings, DatapointKindString, []int64{3000}, nil},
+		{"Aggregate", aggregates, DatapointKindAggregate, []int64{0, 3600000}, nil},
+		{"Empty", empty, DatapointKindNone, []int64{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kind := tt.item.Kind(); kind != tt.kind {
+				t.Errorf("Expected kind %s, got %s", tt.kind, kind)
+			}
+			if tt.item.Len() != len(tt.timestamps) {
+				t.Errorf("Expected %d datapoints, got %d", len(tt.timestamps), tt.item.Len())
+			}
+
+			timestamps := tt.item.Timestamps()
+			times := tt.item.Times()
+			if len(timestamps) != len(tt.timestamps) || len(times) != len(tt.timestamps) {
+				t.Fatalf("Expected timestamps %v, got %v and %v", tt.timestamps, timestamps, times)
+			}
+			for i, ts := range tt.timestamps {
+				if timestamps[i] != ts {
+					t.Errorf("Timestamp %d: expected %d, got %d", i, ts, timestamps[i])
+				}
+				if !times[i].Equal(time.UnixMilli(ts)) || times[i].Location() != time.UTC {
+					t.Errorf("Time %d: expected %d in UTC, got %v", i, ts, times[i])
+				}
+			}
+
+			values := tt.item.Values()
+			if (values == nil) != (tt.values == nil) || len(values) != len(tt.values) {
+				t.Fatalf("Expected values %v, got %v", tt.values, values)
+			}
+			for i, expected := range tt.values {
+				if math.IsNaN(expected) {
+					if !math.IsNaN(values[i]) {
+						t.Errorf("Value %d: expected NaN for a null datapoint, got %v", i, values[i])
+					}
+					continue
+				}
+				if values[i] != expected {
+					t.Errorf("Value %d: expected %v, got %v", i, expected, values[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDataPointListItem_AggregateValues(t *testing.T) {
+	aggregates := &DataPointListItem{DatapointType: &DataPointListItem_AggregateDatapoints{
+		AggregateDatapoints: &AggregateDatapoints{Datapoints: []*AggregateDatapoint{
+			{Timestamp: 0, Average: 2, Count: 10},
+			{Timestamp: 3600000, Average: 4, Count: 5},
+		}},
+	}}
+	numeric := &DataPointListItem{DatapointType: &DataPointListItem_NumericDatapoints{
+		NumericDatapoints: &NumericDatapoints{Datapoints: []*NumericDatapoint{{Timestamp: 1000, Value: 1.5}}},
+	}}
+	strings := &DataPointListItem{DatapointType: &DataPointListItem_StringDatapoints{
+		StringDatapoints: &StringDatapoints{Datapoints: []*StringDatapoint{{Timestamp: 1000, Value: "OPEN"}}},
+	}}
+
+	tests := []struct {
+		name      string
+		item      *DataPointListItem
+		aggregate Aggregate
+		expected  []float64
+		wantErr   bool
+	}{
+		{"Average", aggregates, AggregateAverage, []float64{2, 4}, false},
+		{"Count", aggregates, AggregateCount, []float64{10, 5}, false},
+		{"Unknown aggregate", aggregates, Aggregate("median"), nil, true},
+		{"Numeric item", numeric, AggregateAverage, nil, true},
+		{"String item", strings, AggregateAverage, nil, true},
+		{"Empty item", &DataPointListItem{}, AggregateAverage, []float64{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := tt.item.AggregateValues(tt.aggregate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(values) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, values)
+			}
+			for i := range tt.expected {
+				if values[i] != tt.expected[i] {
+					t.Errorf("Value %d: expected %v, got %v", i, tt.expected[i], values[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDatapoint_Time(t *testing.T) {
+	expected := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	ms := expected.UnixMilli()
+
+	tests := []struct {
+		name string
+		got  time.Time
+	}{
+		{"Numeric", (&NumericDatapoint{Timestamp: ms}).Time()},
+		{"String", (&StringDatapoint{Timestamp: ms}).Time()},
+		{"Aggregate", (&AggregateDatapoint{Timestamp: ms}).Time()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.got.Equal(expected) || tt.got.Location() != time.UTC {
+				t.Errorf("Expected %v, got %v", expected, tt.got)
+			}
+		})
+	}
+}
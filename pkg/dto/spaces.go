@@ -0,0 +1,27 @@
+package dto
+
+// SpaceApply creates or updates a space.
+type SpaceApply struct {
+	Space       string `json:"space"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type Space struct {
+	Space           string `json:"space"`
+	Name            string `json:"name,omitempty"`
+	Description     string `json:"description,omitempty"`
+	CreatedTime     int64  `json:"createdTime"`
+	LastUpdatedTime int64  `json:"lastUpdatedTime"`
+	IsGlobal        bool   `json:"isGlobal"`
+}
+
+type SpaceList struct {
+	Items      []Space `json:"items"`
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+// SpaceId identifies a space in byids and delete requests.
+type SpaceId struct {
+	Space string `json:"space"`
+}
@@ -1,5 +1,10 @@
 package dto
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
 type TimeSeries struct {
 	Id                 int64      `json:"id"`
 	ExternalId         string     `json:"externalId,omitempty"`
@@ -47,6 +52,9 @@ type TimeSeriesFilter struct {
 	LastUpdatedTime  *TimestampRange `json:"lastUpdatedTime,omitempty"`
 }
 
+// DataPointsQueryItem selects datapoints for one time series. Start and End
+// take CDF time expressions such as "2d-ago" or "now", or epoch milliseconds
+// formatted as a string (see cdftime.Format), which are sent as numbers.
 type DataPointsQueryItem struct {
 	Id                   int64       `json:"id,omitempty"`
 	ExternalId           string      `json:"externalId,omitempty"`
@@ -66,6 +74,63 @@ type DataPointsQueryItem struct {
 	Cursor               string      `json:"cursor,omitempty"`
 }
 
+func (q DataPointsQueryItem) MarshalJSON() ([]byte, error) {
+	type plain DataPointsQueryItem
+	return json.Marshal(struct {
+		plain
+		Start interface{} `json:"start,omitempty"`
+		End   interface{} `json:"end,omitempty"`
+	}{plain(q), timeValue(q.Start), timeValue(q.End)})
+}
+
+func (q *DataPointsQueryItem) UnmarshalJSON(data []byte) error {
+	type plain DataPointsQueryItem
+	var raw struct {
+		plain
+		Start json.RawMessage `json:"start,omitempty"`
+		End   json.RawMessage `json:"end,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*q = DataPointsQueryItem(raw.plain)
+	var err error
+	if q.Start, err = timeString(raw.Start); err != nil {
+		return err
+	}
+	q.End, err = timeString(raw.End)
+	return err
+}
+
+// timeValue returns epoch milliseconds as a number and other time
+// expressions as they are. Empty values are left out.
+func timeValue(expr string) interface{} {
+	if expr == "" {
+		return nil
+	}
+	if ms, err := strconv.ParseInt(expr, 10, 64); err == nil {
+		return ms
+	}
+	return expr
+}
+
+// timeString is the inverse of timeValue.
+func timeString(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	if raw[0] == '"' {
+		var expr string
+		err := json.Unmarshal(raw, &expr)
+		return expr, err
+	}
+	var ms int64
+	if err := json.Unmarshal(raw, &ms); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(ms, 10), nil
+}
+
 type LatestDataPointsQueryItem struct {
 	Id                  int64       `json:"id,omitempty"`
 	ExternalId          string      `json:"externalId,omitempty"`
@@ -77,3 +142,137 @@ type LatestDataPointsQueryItem struct {
 	IgnoreBadDataPoints bool        `json:"ignoreBadDataPoints,omitempty"`
 	TreatUncertainAsBad bool        `json:"treatUncertainAsBad,omitempty"`
 }
+
+type TimeSeriesPatch struct {
+	ExternalId         *StringPatch    `json:"externalId,omitempty"`
+	Name               *StringPatch    `json:"name,omitempty"`
+	Metadata           *MetadataPatch  `json:"metadata,omitempty"`
+	Unit               *StringPatch    `json:"unit,omitempty"`
+	UnitExternalId     *StringPatch    `json:"unitExternalId,omitempty"`
+	AssetId            *Int64Patch     `json:"assetId,omitempty"`
+	IsStep             *BoolPatch      `json:"isStep,omitempty"`
+	Description        *StringPatch    `json:"description,omitempty"`
+	SecurityCategories *Int64ListPatch `json:"securityCategories,omitempty"`
+	DataSetID          *Int64Patch     `json:"dataSetId,omitempty"`
+}
+
+type TimeSeriesUpdate struct {
+	Id         int64           `json:"id,omitempty"`
+	ExternalId string          `json:"externalId,omitempty"`
+	InstanceId *InstanceId     `json:"instanceId,omitempty"`
+	Update     TimeSeriesPatch `json:"update"`
+}
+
+type TimeSeriesSearch struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Query       string `json:"query,omitempty"`
+}
+
+const (
+	TimeSeriesAggregateCount                 = "count"
+	TimeSeriesAggregateUniqueValues          = "uniqueValues"
+	TimeSeriesAggregateUniqueProperties      = "uniqueProperties"
+	TimeSeriesAggregateCardinalityValues     = "cardinalityValues"
+	TimeSeriesAggregateCardinalityProperties = "cardinalityProperties"
+)
+
+type TimeSeriesAggregateProperty struct {
+	Property []string `json:"property"`
+}
+
+type TimeSeriesAggregateRequest struct {
+	Aggregate       string                        `json:"aggregate,omitempty"`
+	Properties      []TimeSeriesAggregateProperty `json:"properties,omitempty"`
+	Path            []string                      `json:"path,omitempty"`
+	Filter          *TimeSeriesFilter             `json:"filter,omitempty"`
+	AdvancedFilter  map[string]interface{}        `json:"advancedFilter,omitempty"`
+	AggregateFilter map[string]interface{}        `json:"aggregateFilter,omitempty"`
+}
+
+type TimeSeriesAggregateItem struct {
+	Count  int64         `json:"count"`
+	Values []interface{} `json:"values,omitempty"`
+}
+
+type TimeSeriesAggregateList struct {
+	Items []TimeSeriesAggregateItem `json:"items"`
+}
+
+type SyntheticQueryItem struct {
+	Expression string `json:"expression"`
+	Start      string `json:"start,omitempty"`
+	End        string `json:"end,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// SyntheticDatapoint is one point of a synthetic query result. Numeric
+// results carry Value and string results carry StringValue, which is a
+// pointer so that an empty string is told apart from a numeric point. A
+// point that failed to evaluate has neither and reports why in Error.
+type SyntheticDatapoint struct {
+	Timestamp   int64
+	Value       float64
+	StringValue *string
+	Error       string
+}
+
+// syntheticDatapointJSON is the wire form of SyntheticDatapoint, with the
+// value left raw because it is a number or a string depending on the result.
+type syntheticDatapointJSON struct {
+	Timestamp int64           `json:"timestamp"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+func (d *SyntheticDatapoint) UnmarshalJSON(data []byte) error {
+	var raw syntheticDatapointJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*d = SyntheticDatapoint{Timestamp: raw.Timestamp, Error: raw.Error}
+	if len(raw.Value) == 0 || string(raw.Value) == "null" {
+		return nil
+	}
+	if raw.Value[0] == '"' {
+		d.StringValue = new(string)
+		return json.Unmarshal(raw.Value, d.StringValue)
+	}
+	return json.Unmarshal(raw.Value, &d.Value)
+}
+
+func (d SyntheticDatapoint) MarshalJSON() ([]byte, error) {
+	raw := syntheticDatapointJSON{Timestamp: d.Timestamp, Error: d.Error}
+	var err error
+	switch {
+	case d.Error != "":
+	case d.StringValue != nil:
+		raw.Value, err = json.Marshal(*d.StringValue)
+	default:
+		raw.Value, err = json.Marshal(d.Value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+type SyntheticQueryResult struct {
+	IsString   bool                 `json:"isString"`
+	Datapoints []SyntheticDatapoint `json:"datapoints"`
+}
+
+type SyntheticQueryResponse struct {
+	Items []SyntheticQueryResult `json:"items"`
+}
+
+// DataPointsDeleteItem selects the datapoints in [InclusiveBegin, ExclusiveEnd)
+// to delete. The bounds accept epoch milliseconds (int64), time.Time values
+// and CDF time strings such as "2d-ago" or "now".
+type DataPointsDeleteItem struct {
+	Id             int64       `json:"id,omitempty"`
+	ExternalId     string      `json:"externalId,omitempty"`
+	InstanceId     *InstanceId `json:"instanceId,omitempty"`
+	InclusiveBegin interface{} `json:"inclusiveBegin"`
+	ExclusiveEnd   interface{} `json:"exclusiveEnd,omitempty"`
+}
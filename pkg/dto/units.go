@@ -20,3 +20,20 @@ type Unit struct {
 type UnitList struct {
 	Items []Unit `json:"items"`
 }
+
+// UnitSystemQuantity maps a quantity to its unit in a unit system.
+type UnitSystemQuantity struct {
+	Name           string `json:"name"`
+	UnitExternalId string `json:"unitExternalId"`
+}
+
+// UnitSystem is a unit system such as SI or Imperial, with the unit it uses
+// for each quantity.
+type UnitSystem struct {
+	Name       string               `json:"name"`
+	Quantities []UnitSystemQuantity `json:"quantities"`
+}
+
+type UnitSystemList struct {
+	Items []UnitSystem `json:"items"`
+}
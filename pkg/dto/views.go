@@ -0,0 +1,217 @@
+package dto
+
+import "encoding/json"
+
+// Connection types of view properties that are not backed by a container
+// property.
+const (
+	ConnectionTypeSingleEdge                  = "single_edge_connection"
+	ConnectionTypeMultiEdge                   = "multi_edge_connection"
+	ConnectionTypeSingleReverseDirectRelation = "single_reverse_direct_relation"
+	ConnectionTypeMultiReverseDirectRelation  = "multi_reverse_direct_relation"
+)
+
+// Directions of edge connections.
+const (
+	EdgeDirectionOutwards = "outwards"
+	EdgeDirectionInwards  = "inwards"
+)
+
+// ViewId identifies a view in byids and delete requests.
+type ViewId struct {
+	Space      string `json:"space"`
+	ExternalId string `json:"externalId"`
+	Version    string `json:"version"`
+}
+
+// DirectRelationReference points at a node, such as the type of the edges
+// of an edge connection.
+type DirectRelationReference struct {
+	Space      string `json:"space"`
+	ExternalId string `json:"externalId"`
+}
+
+// SourceReference points at a view or a container. Type is "view" or
+// "container", and Version is only set for views.
+type SourceReference struct {
+	Type       string `json:"type"`
+	Space      string `json:"space"`
+	ExternalId string `json:"externalId"`
+	Version    string `json:"version,omitempty"`
+}
+
+// ThroughReference is the direct relation property a reverse direct
+// relation follows back to the view.
+type ThroughReference struct {
+	Source     SourceReference `json:"source"`
+	Identifier string          `json:"identifier"`
+}
+
+// ViewPropertyApply defines a view property. Set Container and
+// ContainerPropertyIdentifier to map a container property, or ConnectionType
+// with Source and either Type and Direction (edges) or Through (reverse
+// direct relations) to define a connection.
+type ViewPropertyApply struct {
+	Container                   *ContainerReference      `json:"container,omitempty"`
+	ContainerPropertyIdentifier string                   `json:"containerPropertyIdentifier,omitempty"`
+	Name                        string                   `json:"name,omitempty"`
+	Description                 string                   `json:"description,omitempty"`
+	Source                      *ViewReference           `json:"source,omitempty"`
+	ConnectionType              string                   `json:"connectionType,omitempty"`
+	Type                        *DirectRelationReference `json:"type,omitempty"`
+	EdgeSource                  *ViewReference           `json:"edgeSource,omitempty"`
+	Direction                   string                   `json:"direction,omitempty"`
+	Through                     *ThroughReference        `json:"through,omitempty"`
+}
+
+// ViewProperty is a property of a retrieved view. Container properties have
+// Container, ContainerPropertyIdentifier and the container property
+// definition in Type, Nullable and the like. Connections have a
+// ConnectionType, and edge connections keep the edge type in EdgeType, since
+// CDF sends both under "type". As in ContainerPropertyDefinition, Nullable is
+// a pointer because CDF treats a missing value as true.
+type ViewProperty struct {
+	Container                   *ContainerReference
+	ContainerPropertyIdentifier string
+	Type                        *PropertyType
+	Nullable                    *bool
+	Immutable                   bool
+	AutoIncrement               bool
+	DefaultValue                interface{}
+	Name                        string
+	Description                 string
+	Source                      *ViewReference
+	ConnectionType              string
+	EdgeType                    *DirectRelationReference
+	EdgeSource                  *ViewReference
+	Direction                   string
+	Through                     *ThroughReference
+}
+
+// viewPropertyJSON is the wire form of ViewProperty, with "type" left raw
+// until the connection type tells how to decode it.
+type viewPropertyJSON struct {
+	Container                   *ContainerReference `json:"container,omitempty"`
+	ContainerPropertyIdentifier string              `json:"containerPropertyIdentifier,omitempty"`
+	Type                        json.RawMessage     `json:"type,omitempty"`
+	Nullable                    *bool               `json:"nullable,omitempty"`
+	Immutable                   bool                `json:"immutable,omitempty"`
+	AutoIncrement               bool                `json:"autoIncrement,omitempty"`
+	DefaultValue                interface{}         `json:"defaultValue,omitempty"`
+	Name                        string              `json:"name,omitempty"`
+	Description                 string              `json:"description,omitempty"`
+	Source                      *ViewReference      `json:"source,omitempty"`
+	ConnectionType              string              `json:"connectionType,omitempty"`
+	EdgeSource                  *ViewReference      `json:"edgeSource,omitempty"`
+	Direction                   string              `json:"direction,omitempty"`
+	Through                     *ThroughReference   `json:"through,omitempty"`
+}
+
+// IsConnection reports whether the property is an edge or reverse direct
+// relation connection rather than a container property.
+func (p ViewProperty) IsConnection() bool {
+	return p.ConnectionType != ""
+}
+
+// IsEdge reports whether the property is an edge connection.
+func (p ViewProperty) IsEdge() bool {
+	return p.ConnectionType == ConnectionTypeSingleEdge || p.ConnectionType == ConnectionTypeMultiEdge
+}
+
+func (p *ViewProperty) UnmarshalJSON(data []byte) error {
+	var raw viewPropertyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = ViewProperty{
+		Container:                   raw.Container,
+		ContainerPropertyIdentifier: raw.ContainerPropertyIdentifier,
+		Nullable:                    raw.Nullable,
+		Immutable:                   raw.Immutable,
+		AutoIncrement:               raw.AutoIncrement,
+		DefaultValue:                raw.DefaultValue,
+		Name:                        raw.Name,
+		Description:                 raw.Description,
+		Source:                      raw.Source,
+		ConnectionType:              raw.ConnectionType,
+		EdgeSource:                  raw.EdgeSource,
+		Direction:                   raw.Direction,
+		Through:                     raw.Through,
+	}
+	if len(raw.Type) == 0 || string(raw.Type) == "null" {
+		return nil
+	}
+	if p.IsEdge() {
+		p.EdgeType = &DirectRelationReference{}
+		return json.Unmarshal(raw.Type, p.EdgeType)
+	}
+	p.Type = &PropertyType{}
+	return json.Unmarshal(raw.Type, p.Type)
+}
+
+func (p ViewProperty) MarshalJSON() ([]byte, error) {
+	raw := viewPropertyJSON{
+		Container:                   p.Container,
+		ContainerPropertyIdentifier: p.ContainerPropertyIdentifier,
+		Nullable:                    p.Nullable,
+		Immutable:                   p.Immutable,
+		AutoIncrement:               p.AutoIncrement,
+		DefaultValue:                p.DefaultValue,
+		Name:                        p.Name,
+		Description:                 p.Description,
+		Source:                      p.Source,
+		ConnectionType:              p.ConnectionType,
+		EdgeSource:                  p.EdgeSource,
+		Direction:                   p.Direction,
+		Through:                     p.Through,
+	}
+	var err error
+	switch {
+	case p.EdgeType != nil:
+		raw.Type, err = json.Marshal(p.EdgeType)
+	case p.Type != nil:
+		raw.Type, err = json.Marshal(p.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+// ViewApply creates or updates a version of a view.
+type ViewApply struct {
+	Space       string                       `json:"space"`
+	ExternalId  string                       `json:"externalId"`
+	Version     string                       `json:"version"`
+	Name        string                       `json:"name,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	Filter      map[string]interface{}       `json:"filter,omitempty"`
+	Implements  []ViewReference              `json:"implements,omitempty"`
+	Properties  map[string]ViewPropertyApply `json:"properties"`
+}
+
+type View struct {
+	Space           string                  `json:"space"`
+	ExternalId      string                  `json:"externalId"`
+	Version         string                  `json:"version"`
+	Name            string                  `json:"name,omitempty"`
+	Description     string                  `json:"description,omitempty"`
+	Filter          map[string]interface{}  `json:"filter,omitempty"`
+	Implements      []ViewReference         `json:"implements,omitempty"`
+	Writable        bool                    `json:"writable"`
+	UsedFor         string                  `json:"usedFor"`
+	Properties      map[string]ViewProperty `json:"properties"`
+	CreatedTime     int64                   `json:"createdTime"`
+	LastUpdatedTime int64                   `json:"lastUpdatedTime"`
+	IsGlobal        bool                    `json:"isGlobal"`
+}
+
+// Id returns the identifier of the view.
+func (v View) Id() ViewId {
+	return ViewId{Space: v.Space, ExternalId: v.ExternalId, Version: v.Version}
+}
+
+type ViewList struct {
+	Items      []View  `json:"items"`
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,280 @@
+// Package export writes datapoints and time series metadata to file formats
+// used outside CDF.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+	"github.com/evertoncolling/poc-requests-go/pkg/frame"
+)
+
+// TimestampFormat controls how timestamps are written.
+type TimestampFormat int
+
+const (
+	// EpochMillis writes milliseconds since the Unix epoch.
+	EpochMillis TimestampFormat = iota
+	// RFC3339 writes RFC 3339 timestamps with millisecond precision.
+	RFC3339
+)
+
+// rfc3339Millis is RFC 3339 with a fixed millisecond fraction.
+const rfc3339Millis = "2006-01-02T15:04:05.000Z07:00"
+
+// CSVOptions controls the CSV writers.
+type CSVOptions struct {
+	Timestamps TimestampFormat
+	// Location is the time zone of RFC 3339 timestamps. It defaults to UTC.
+	Location *time.Location
+	// Aggregates lists the aggregates to write for aggregate items, such as
+	// dto.AggregateAverage. Each becomes a series named "series|aggregate".
+	Aggregates []dto.Aggregate
+}
+
+func (o CSVOptions) formatTimestamp(ts int64) string {
+	if o.Timestamps == EpochMillis {
+		return strconv.FormatInt(ts, 10)
+	}
+	location := o.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return time.UnixMilli(ts).In(location).Format(rfc3339Millis)
+}
+
+// csvColumn is one exported series: a raw numeric or string series, or one
+// aggregate of an aggregate series.
+type csvColumn struct {
+	name       string
+	timestamps []int64
+	value      func(i int) string
+	status     func(i int) string
+}
+
+func datapointColumns(item *dto.DataPointListItem, aggregates []dto.Aggregate) ([]csvColumn, error) {
+	series := frame.SeriesName(item)
+	switch item.Kind() {
+	case dto.DatapointKindNumeric:
+		datapoints := item.Numeric()
+		return []csvColumn{{
+			name:       series,
+			timestamps: item.Timestamps(),
+			value: func(i int) string {
+				if datapoints[i].GetNullValue() {
+					return ""
+				}
+				return formatFloat(datapoints[i].GetValue())
+			},
+			status: func(i int) string { return formatStatus(datapoints[i].GetStatus()) },
+		}}, nil
+	case dto.DatapointKindString:
+		datapoints := item.Strings()
+		return []csvColumn{{
+			name:       series,
+			timestamps: item.Timestamps(),
+			value:      func(i int) string { return datapoints[i].GetValue() },
+			status:     func(i int) string { return formatStatus(datapoints[i].GetStatus()) },
+		}}, nil
+	case dto.DatapointKindAggregate:
+		if len(aggregates) == 0 {
+			return nil, fmt.Errorf("aggregate datapoints of %s need the aggregate names to export", series)
+		}
+		columns := make([]csvColumn, 0, len(aggregates))
+		for _, aggregate := range aggregates {
+			values, err := item.AggregateValues(aggregate)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, csvColumn{
+				name:       frame.ColumnKey{Series: series, Aggregate: aggregate}.String(),
+				timestamps: item.Timestamps(),
+				value:      func(i int) string { return formatFloat(values[i]) },
+				status:     func(int) string { return "" },
+			})
+		}
+		return columns, nil
+	default:
+		return nil, nil
+	}
+}
+
+func formatFloat(value float64) string {
+	if math.IsNaN(value) {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// formatStatus writes the status symbol, or the code when there is no
+// symbol. Good datapoints carry no status and get an empty cell.
+func formatStatus(status *dto.Status) string {
+	switch {
+	case status.GetSymbol() != "":
+		return status.GetSymbol()
+	case status.GetCode() != 0:
+		return strconv.FormatInt(status.GetCode(), 10)
+	default:
+		return ""
+	}
+}
+
+// WriteDatapointsLong writes one row per datapoint with the columns series,
+// timestamp, value and status. Rows are grouped by series in response order.
+func WriteDatapointsLong(w io.Writer, response *dto.DataPointListResponse, options CSVOptions) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"series", "timestamp", "value", "status"}); err != nil {
+		return err
+	}
+
+	for _, item := range response.GetItems() {
+		columns, err := datapointColumns(item, options.Aggregates)
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			for i, ts := range column.timestamps {
+				row := []string{column.name, options.formatTimestamp(ts), column.value(i), column.status(i)}
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteDatapointsWide writes one row per distinct timestamp and one column
+// per series, leaving cells empty where a series has no datapoint. Status
+// codes are not included.
+func WriteDatapointsWide(w io.Writer, response *dto.DataPointListResponse, options CSVOptions) error {
+	var columns []csvColumn
+	for _, item := range response.GetItems() {
+		itemColumns, err := datapointColumns(item, options.Aggregates)
+		if err != nil {
+			return err
+		}
+		columns = append(columns, itemColumns...)
+	}
+
+	writer := csv.NewWriter(w)
+	header := []string{"timestamp"}
+	for _, column := range columns {
+		header = append(header, column.name)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// Merge the sorted columns one timestamp at a time
+	positions := make([]int, len(columns))
+	row := make([]string, len(columns)+1)
+	for {
+		next := int64(math.MaxInt64)
+		done := true
+		for c, column := range columns {
+			if positions[c] < len(column.timestamps) {
+				next = min(next, column.timestamps[positions[c]])
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+
+		row[0] = options.formatTimestamp(next)
+		for c, column := range columns {
+			row[c+1] = ""
+			if positions[c] < len(column.timestamps) && column.timestamps[positions[c]] == next {
+				row[c+1] = column.value(positions[c])
+				positions[c]++
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteTimeSeries writes one row per time series. Metadata is flattened into
+// one "metadata.<key>" column per key found on any of the time series.
+func WriteTimeSeries(w io.Writer, list dto.TimeSeriesList, options CSVOptions) error {
+	var metadataKeys []string
+	for i := range list.Items {
+		for key := range list.Items[i].Metadata {
+			if !slices.Contains(metadataKeys, key) {
+				metadataKeys = append(metadataKeys, key)
+			}
+		}
+	}
+	slices.Sort(metadataKeys)
+
+	writer := csv.NewWriter(w)
+	header := []string{
+		"id", "externalId", "instanceId", "name", "isString", "isStep", "unit", "unitExternalId",
+		"assetId", "dataSetId", "description", "securityCategories", "createdTime", "lastUpdatedTime",
+	}
+	for _, key := range metadataKeys {
+		header = append(header, "metadata."+key)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for i := range list.Items {
+		ts := &list.Items[i]
+		instanceId := ""
+		if ts.InstanceId.Space != "" || ts.InstanceId.ExternalId != "" {
+			instanceId = ts.InstanceId.Space + ":" + ts.InstanceId.ExternalId
+		}
+		categories := make([]string, len(ts.SecurityCategories))
+		for j, category := range ts.SecurityCategories {
+			categories[j] = strconv.FormatInt(category, 10)
+		}
+
+		row := []string{
+			strconv.FormatInt(ts.Id, 10),
+			ts.ExternalId,
+			instanceId,
+			ts.Name,
+			strconv.FormatBool(ts.IsString),
+			strconv.FormatBool(ts.IsStep),
+			ts.Unit,
+			ts.UnitExternalId,
+			formatOptionalId(ts.AssetId),
+			formatOptionalId(ts.DataSetID),
+			ts.Description,
+			strings.Join(categories, ";"),
+			options.formatTimestamp(ts.CreatedTime),
+			options.formatTimestamp(ts.LastUpdatedTime),
+		}
+		for _, key := range metadataKeys {
+			row = append(row, ts.Metadata[key])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatOptionalId(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
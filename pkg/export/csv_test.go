@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func testResponse() *dto.DataPointListResponse {
+	return &dto.DataPointListResponse{Items: []*dto.DataPointListItem{
+		{
+			ExternalId: "temp",
+			DatapointType: &dto.DataPointListItem_NumericDatapoints{
+				NumericDatapoints: &dto.NumericDatapoints{Datapoints: []*dto.NumericDatapoint{
+					{Timestamp: 0, Value: 1.5},
+					{Timestamp: 2000, NullValue: true, Status: &dto.Status{Code: 2147483648, Symbol: "Bad"}},
+				}},
+			},
+		},
+		{
+			ExternalId: "state",
+			DatapointType: &dto.DataPointListItem_StringDatapoints{
+				StringDatapoints: &dto.StringDatapoints{Datapoints: []*dto.StringDatapoint{
+					{Timestamp: 1000, Value: "running, fast"},
+				}},
+			},
+		},
+	}}
+}
+
+func TestWriteDatapointsLong(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDatapointsLong(&buf, testResponse(), CSVOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "series,timestamp,value,status\n" +
+		"temp,0,1.5,\n" +
+		"temp,2000,,Bad\n" +
+		"state,1000,\"running, fast\",\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteDatapointsWide(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("Time zone database not available: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		options  CSVOptions
+		expected string
+	}{
+		{
+			name:    "epoch",
+			options: CSVOptions{},
+			expected: "timestamp,temp,state\n" +
+				"0,1.5,\n" +
+				"1000,,\"running, fast\"\n" +
+				"2000,,\n",
+		},
+		{
+			name:    "time zone",
+			options: CSVOptions{Timestamps: RFC3339, Location: oslo},
+			expected: "timestamp,temp,state\n" +
+				"1970-01-01T01:00:00.000+01:00,1.5,\n" +
+				"1970-01-01T01:00:01.000+01:00,,\"running, fast\"\n" +
+				"1970-01-01T01:00:02.000+01:00,,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteDatapointsWide(&buf, testResponse(), tt.options); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteDatapointsWide_aggregates(t *testing.T) {
+	response := &dto.DataPointListResponse{Items: []*dto.DataPointListItem{{
+		Id: 7,
+		DatapointType: &dto.DataPointListItem_AggregateDatapoints{
+			AggregateDatapoints: &dto.AggregateDatapoints{Datapoints: []*dto.AggregateDatapoint{
+				{Timestamp: 0, Average: 2, Count: 10},
+			}},
+		},
+	}}}
+
+	if err := WriteDatapointsWide(&bytes.Buffer{}, response, CSVOptions{}); err == nil {
+		t.Error("Expected an error without aggregate names")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDatapointsWide(&buf, response, CSVOptions{Aggregates: []dto.Aggregate{dto.AggregateAverage, dto.AggregateCount}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "timestamp,7|average,7|count\n0,2,10\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteTimeSeries(t *testing.T) {
+	list := dto.TimeSeriesList{Items: []dto.TimeSeries{
+		{
+			Id:                 1,
+			ExternalId:         "temp",
+			Name:               "Temperature",
+			Unit:               "degC",
+			Metadata:           dto.Metadata{"site": "Oslo", "area": "A"},
+			SecurityCategories: []int64{3, 4},
+			CreatedTime:        0,
+			LastUpdatedTime:    1000,
+		},
+		{
+			Id:       2,
+			IsString: true,
+			Metadata: dto.Metadata{"owner": "ops"},
+			AssetId:  5,
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteTimeSeries(&buf, list, CSVOptions{Timestamps: RFC3339}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "id,externalId,instanceId,name,isString,isStep,unit,unitExternalId,assetId,dataSetId,description," +
+		"securityCategories,createdTime,lastUpdatedTime,metadata.area,metadata.owner,metadata.site\n" +
+		"1,temp,,Temperature,false,false,degC,,,,,3;4,1970-01-01T00:00:00.000Z,1970-01-01T00:00:01.000Z,A,,Oslo\n" +
+		"2,,,,true,false,,,5,,,,1970-01-01T00:00:00.000Z,1970-01-01T00:00:00.000Z,,ops,\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,250 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+	"github.com/evertoncolling/poc-requests-go/pkg/frame"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ParquetValueType is the type of the value column.
+type ParquetValueType int
+
+const (
+	// ParquetDouble stores numeric and aggregate datapoints as DOUBLE.
+	ParquetDouble ParquetValueType = iota
+	// ParquetString stores string datapoints as UTF-8 BYTE_ARRAY.
+	ParquetString
+)
+
+// ParquetCompression is the codec used for data pages.
+type ParquetCompression int
+
+const (
+	ParquetUncompressed ParquetCompression = iota
+	ParquetGzip
+)
+
+// ParquetOptions controls a ParquetWriter.
+type ParquetOptions struct {
+	ValueType   ParquetValueType
+	Compression ParquetCompression
+	// RowGroupSize is the number of rows buffered before a row group is
+	// written. It defaults to 500000.
+	RowGroupSize int
+	// Aggregates lists the aggregates to write for aggregate items. Each
+	// becomes a series named "series|aggregate".
+	Aggregates []dto.Aggregate
+}
+
+// ParquetWriter streams datapoints to a Parquet file in long format, with the
+// columns series (UTF-8), timestamp (INT64 TIMESTAMP_MILLIS), value (optional
+// DOUBLE or UTF-8, null for null datapoints) and status_code (INT64, 0 for
+// Good). Rows are buffered until RowGroupSize is reached and then written as
+// a row group, so memory use is bounded by one row group.
+//
+// Close must be called to write the file footer.
+type ParquetWriter struct {
+	writer   *parquet.Writer
+	options  ParquetOptions
+	buffered int
+	closed   bool
+}
+
+// parquetDoubleRow and parquetStringRow are the rows of files with a double
+// and a string value column.
+type parquetDoubleRow struct {
+	Series     string   `parquet:"series,dict"`
+	Timestamp  int64    `parquet:"timestamp,timestamp(millisecond)"`
+	Value      *float64 `parquet:"value,optional"`
+	StatusCode int64    `parquet:"status_code"`
+}
+
+type parquetStringRow struct {
+	Series     string  `parquet:"series,dict"`
+	Timestamp  int64   `parquet:"timestamp,timestamp(millisecond)"`
+	Value      *string `parquet:"value,optional"`
+	StatusCode int64   `parquet:"status_code"`
+}
+
+// NewParquetWriter returns a writer for the datapoints that writes the
+// Parquet file to w.
+func NewParquetWriter(w io.Writer, options ParquetOptions) (*ParquetWriter, error) {
+	if options.RowGroupSize <= 0 {
+		options.RowGroupSize = 500000
+	}
+
+	var schema *parquet.Schema
+	switch options.ValueType {
+	case ParquetDouble:
+		schema = parquet.SchemaOf(parquetDoubleRow{})
+	case ParquetString:
+		schema = parquet.SchemaOf(parquetStringRow{})
+	default:
+		return nil, fmt.Errorf("unknown parquet value type %d", options.ValueType)
+	}
+	writerOptions := []parquet.WriterOption{schema, parquet.CreatedBy("poc-requests-go", "", "")}
+	switch options.Compression {
+	case ParquetUncompressed:
+	case ParquetGzip:
+		writerOptions = append(writerOptions, parquet.Compression(&parquet.Gzip))
+	default:
+		return nil, fmt.Errorf("unknown parquet compression %d", options.Compression)
+	}
+
+	return &ParquetWriter{writer: parquet.NewWriter(w, writerOptions...), options: options}, nil
+}
+
+// WriteResponse writes the datapoints of every item in the response.
+func (p *ParquetWriter) WriteResponse(response *dto.DataPointListResponse) error {
+	for _, item := range response.GetItems() {
+		if err := p.WriteItem(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePage writes one page of datapoints. Its signature matches the page
+// callback of TimeSeries.RetrieveAllFunc, so large fetches can be streamed
+// to disk with RetrieveAllFunc(..., writer.WritePage).
+func (p *ParquetWriter) WritePage(index int, page *dto.DataPointListItem) error {
+	return p.WriteItem(page)
+}
+
+// WriteItem writes the datapoints of one item.
+func (p *ParquetWriter) WriteItem(item *dto.DataPointListItem) error {
+	if p.closed {
+		return errors.New("parquet writer is closed")
+	}
+	series := frame.SeriesName(item)
+	kind := item.Kind()
+	if (kind == dto.DatapointKindString) != (p.options.ValueType == ParquetString) && kind != dto.DatapointKindNone {
+		return fmt.Errorf("cannot write %s datapoints of %s to a %s value column", kind, series, p.valueTypeName())
+	}
+
+	switch kind {
+	case dto.DatapointKindNumeric:
+		for _, dp := range item.Numeric() {
+			row := &parquetDoubleRow{Series: series, Timestamp: dp.GetTimestamp(), StatusCode: int64(dp.GetStatus().GetCode())}
+			if !dp.GetNullValue() {
+				value := dp.GetValue()
+				row.Value = &value
+			}
+			if err := p.write(row); err != nil {
+				return err
+			}
+		}
+	case dto.DatapointKindString:
+		for _, dp := range item.Strings() {
+			row := &parquetStringRow{Series: series, Timestamp: dp.GetTimestamp(), StatusCode: int64(dp.GetStatus().GetCode())}
+			if !dp.GetNullValue() {
+				value := dp.GetValue()
+				row.Value = &value
+			}
+			if err := p.write(row); err != nil {
+				return err
+			}
+		}
+	case dto.DatapointKindAggregate:
+		if len(p.options.Aggregates) == 0 {
+			return fmt.Errorf("aggregate datapoints of %s need the aggregate names to export", series)
+		}
+		timestamps := item.Timestamps()
+		for _, aggregate := range p.options.Aggregates {
+			values, err := item.AggregateValues(aggregate)
+			if err != nil {
+				return err
+			}
+			name := frame.ColumnKey{Series: series, Aggregate: aggregate}.String()
+			if err := p.writeDoubles(name, timestamps, values); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteFrame writes every column of a frame as a series named after its
+// column key. Missing (NaN) values are skipped.
+func (p *ParquetWriter) WriteFrame(f *frame.Frame) error {
+	if p.closed {
+		return errors.New("parquet writer is closed")
+	}
+	if p.options.ValueType != ParquetDouble {
+		return errors.New("frames can only be written to a double value column")
+	}
+	for _, key := range f.Keys() {
+		values, _ := f.Column(key)
+		if err := p.writeDoubles(key.String(), f.Timestamps(), values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDoubles writes the non-NaN values of one series.
+func (p *ParquetWriter) writeDoubles(series string, timestamps []int64, values []float64) error {
+	for i, value := range values {
+		if math.IsNaN(value) {
+			continue
+		}
+		if err := p.write(&parquetDoubleRow{Series: series, Timestamp: timestamps[i], Value: &value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ParquetWriter) valueTypeName() string {
+	if p.options.ValueType == ParquetString {
+		return "string"
+	}
+	return "double"
+}
+
+// write buffers one row and writes a row group once RowGroupSize rows are
+// buffered.
+func (p *ParquetWriter) write(row interface{}) error {
+	if err := p.writer.Write(row); err != nil {
+		return fmt.Errorf("failed to write parquet row: %w", err)
+	}
+	p.buffered++
+	if p.buffered < p.options.RowGroupSize {
+		return nil
+	}
+	return p.Flush()
+}
+
+// Flush writes the buffered rows as a row group.
+func (p *ParquetWriter) Flush() error {
+	if p.closed {
+		return errors.New("parquet writer is closed")
+	}
+	if p.buffered == 0 {
+		return nil
+	}
+	if err := p.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write parquet row group: %w", err)
+	}
+	p.buffered = 0
+	return nil
+}
+
+// Close writes the remaining rows and the file footer. It does not close the
+// underlying writer.
+func (p *ParquetWriter) Close() error {
+	if p.closed {
+		return nil
+	}
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	p.closed = true
+	return p.writer.Close()
+}
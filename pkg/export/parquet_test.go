@@ -0,0 +1,234 @@
+package export
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/api"
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+	"google.golang.org/protobuf/proto"
+)
+
+type parquetRow struct {
+	series    string
+	timestamp int64
+	value     interface{}
+	status    int64
+}
+
+// readParquet opens a file written by ParquetWriter and reads back its rows
+func readParquet(t *testing.T, data []byte, valueType ParquetValueType) (*parquet.File, []parquetRow) {
+	t.Helper()
+
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to open parquet file: %v", err)
+	}
+
+	var rows []parquetRow
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+	for i := int64(0); i < file.NumRows(); i++ {
+		if valueType == ParquetString {
+			var row parquetStringRow
+			if err := reader.Read(&row); err != nil {
+				t.Fatalf("Failed to read row %d: %v", i, err)
+			}
+			var value interface{}
+			if row.Value != nil {
+				value = *row.Value
+			}
+			rows = append(rows, parquetRow{row.Series, row.Timestamp, value, row.StatusCode})
+			continue
+		}
+		var row parquetDoubleRow
+		if err := reader.Read(&row); err != nil {
+			t.Fatalf("Failed to read row %d: %v", i, err)
+		}
+		var value interface{}
+		if row.Value != nil {
+			value = *row.Value
+		}
+		rows = append(rows, parquetRow{row.Series, row.Timestamp, value, row.StatusCode})
+	}
+	return file, rows
+}
+
+func TestParquetWriter_numeric(t *testing.T) {
+	response := &dto.DataPointListResponse{Items: []*dto.DataPointListItem{
+		{
+			ExternalId: "temp",
+			DatapointType: &dto.DataPointListItem_NumericDatapoints{
+				NumericDatapoints: &dto.NumericDatapoints{Datapoints: []*dto.NumericDatapoint{
+					{Timestamp: 1000, Value: 1.5},
+					{Timestamp: 2000, NullValue: true, Status: &dto.Status{Code: 2147483648}},
+					{Timestamp: 3000, Value: -2},
+				}},
+			},
+		},
+		{
+			Id: 42,
+			DatapointType: &dto.DataPointListItem_NumericDatapoints{
+				NumericDatapoints: &dto.NumericDatapoints{Datapoints: []*dto.NumericDatapoint{
+					{Timestamp: 1000, Value: 10},
+					{Timestamp: 5000, Value: 50},
+				}},
+			},
+		},
+	}}
+	expected := []parquetRow{
+		{"temp", 1000, 1.5, 0},
+		{"temp", 2000, nil, 2147483648},
+		{"temp", 3000, -2.0, 0},
+		{"42", 1000, 10.0, 0},
+		{"42", 5000, 50.0, 0},
+	}
+
+	tests := []struct {
+		name        string
+		compression ParquetCompression
+		codec       format.CompressionCodec
+	}{
+		{"uncompressed", ParquetUncompressed, format.Uncompressed},
+		{"gzip", ParquetGzip, format.Gzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewParquetWriter(&buf, ParquetOptions{Compression: tt.compression, RowGroupSize: 3})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := writer.WriteResponse(response); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			file, rows := readParquet(t, buf.Bytes(), ParquetDouble)
+			if file.NumRows() != 5 {
+				t.Errorf("Expected 5 rows, got %d", file.NumRows())
+			}
+			if groups := len(file.RowGroups()); groups != 2 {
+				t.Errorf("Expected 2 row groups, got %d", groups)
+			}
+			columns := file.Metadata().RowGroups[0].Columns
+			for i, name := range []string{"series", "timestamp", "value", "status_code"} {
+				if got := file.Schema().Fields()[i].Name(); got != name {
+					t.Errorf("Column %d: expected %s, got %s", i, name, got)
+				}
+				if codec := columns[i].MetaData.Codec; codec != tt.codec {
+					t.Errorf("Column %d: expected codec %s, got %s", i, tt.codec, codec)
+				}
+			}
+			timestamp := file.Schema().Fields()[1].Type().LogicalType()
+			if timestamp == nil || timestamp.Timestamp == nil || timestamp.Timestamp.Unit.Millis == nil {
+				t.Errorf("Expected a millisecond timestamp column, got %v", timestamp)
+			}
+
+			if len(rows) != len(expected) {
+				t.Fatalf("Expected %d rows, got %d", len(expected), len(rows))
+			}
+			for i := range expected {
+				if rows[i] != expected[i] {
+					t.Errorf("Row %d: expected %v, got %v", i, expected[i], rows[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParquetWriter_strings(t *testing.T) {
+	item := &dto.DataPointListItem{
+		ExternalId: "state",
+		DatapointType: &dto.DataPointListItem_StringDatapoints{
+			StringDatapoints: &dto.StringDatapoints{Datapoints: []*dto.StringDatapoint{
+				{Timestamp: 1000, Value: "running"},
+				{Timestamp: 2000, Value: "stopped"},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	doubles, _ := NewParquetWriter(&buf, ParquetOptions{})
+	if err := doubles.WriteItem(item); err == nil {
+		t.Error("Expected an error writing strings to a double column")
+	}
+
+	buf.Reset()
+	writer, _ := NewParquetWriter(&buf, ParquetOptions{ValueType: ParquetString})
+	if err := writer.WriteItem(item); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, rows := readParquet(t, buf.Bytes(), ParquetString)
+	if len(rows) != 2 || rows[0].value != "running" || rows[1].value != "stopped" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+	if err := writer.WriteItem(item); err == nil {
+		t.Error("Expected an error writing to a closed writer")
+	}
+}
+
+type staticToken string
+
+func (s staticToken) FetchToken() string {
+	return string(s)
+}
+
+func TestParquetWriter_WritePage(t *testing.T) {
+	// Two pages for one series, linked by a cursor
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		item := &dto.DataPointListItem{ExternalId: "pump", NextCursor: "page-2"}
+		points := []*dto.NumericDatapoint{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 2}}
+		if requests > 1 {
+			item.NextCursor = ""
+			points = []*dto.NumericDatapoint{{Timestamp: 3000, Value: 3}}
+		}
+		item.DatapointType = &dto.DataPointListItem_NumericDatapoints{NumericDatapoints: &dto.NumericDatapoints{Datapoints: points}}
+		data, err := proto.Marshal(&dto.DataPointListResponse{Items: []*dto.DataPointListItem{item}})
+		if err != nil {
+			t.Errorf("Failed to encode response: %v", err)
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	client := api.NewCogniteClient(api.ClientConfig{
+		ClientName:  "test-client",
+		Cluster:     "test-cluster",
+		Project:     "test-project",
+		Credentials: staticToken("test-token"),
+	})
+	client.TimeSeries.Client.BaseURL = server.URL
+
+	var buf bytes.Buffer
+	writer, err := NewParquetWriter(&buf, ParquetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	items := []dto.DataPointsQueryItem{{ExternalId: "pump"}}
+	if err := client.TimeSeries.RetrieveAllFunc(&items, nil, nil, nil, nil, nil, nil, nil, nil, writer.WritePage); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, rows := readParquet(t, buf.Bytes(), ParquetDouble)
+	if requests != 2 || len(rows) != 3 || rows[2].timestamp != 3000 || rows[2].value != 3.0 {
+		t.Errorf("Expected 3 rows from 2 pages, got %v from %d requests", rows, requests)
+	}
+}
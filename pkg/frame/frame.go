@@ -0,0 +1,343 @@
+// Package frame aligns the datapoints of many time series into a columnar
+// matrix that is ready for analytics or export.
+package frame
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// ColumnKey identifies a column of a Frame. Aggregate is empty for raw
+// numeric datapoints.
+type ColumnKey struct {
+	Series    string
+	Aggregate dto.Aggregate
+}
+
+func (k ColumnKey) String() string {
+	if k.Aggregate == "" {
+		return k.Series
+	}
+	return k.Series + "|" + string(k.Aggregate)
+}
+
+// JoinType controls which timestamps a join keeps.
+type JoinType int
+
+const (
+	// OuterJoin keeps every timestamp present in any frame.
+	OuterJoin JoinType = iota
+	// InnerJoin keeps only the timestamps present in every frame.
+	InnerJoin
+)
+
+// Frame holds a shared, strictly increasing timestamp index and one float64
+// column per ColumnKey. Null datapoints and timestamps where a series has no
+// datapoint are both NaN, but the frame remembers which is which, so that
+// filling only touches the missing ones. The columns are stored back to back
+// in a single slice, so each column is contiguous in memory.
+type Frame struct {
+	timestamps []int64
+	keys       []ColumnKey
+	values     []float64
+	// present is parallel to values and is false where the series has no
+	// datapoint at the timestamp.
+	present []bool
+}
+
+// New creates a frame from a timestamp index and one column per key.
+// Timestamps must be strictly increasing and every column must be as long as
+// the index. NaN values are taken to be null datapoints, not missing ones.
+func New(timestamps []int64, keys []ColumnKey, columns [][]float64) (*Frame, error) {
+	if len(keys) != len(columns) {
+		return nil, fmt.Errorf("got %d keys for %d columns", len(keys), len(columns))
+	}
+	for i := 1; i < len(timestamps); i++ {
+		if timestamps[i] <= timestamps[i-1] {
+			return nil, fmt.Errorf("timestamps must be strictly increasing (index %d)", i)
+		}
+	}
+	f := newFrame(slices.Clone(timestamps), nil)
+	for i, column := range columns {
+		if len(column) != len(timestamps) {
+			return nil, fmt.Errorf("column %s has %d values for %d timestamps", keys[i], len(column), len(timestamps))
+		}
+		if slices.Contains(f.keys, keys[i]) {
+			return nil, fmt.Errorf("duplicated column %s", keys[i])
+		}
+		f.keys = append(f.keys, keys[i])
+		f.values = append(f.values, column...)
+		for range column {
+			f.present = append(f.present, true)
+		}
+	}
+	return f, nil
+}
+
+// newFrame creates a frame in which every value is missing.
+func newFrame(timestamps []int64, keys []ColumnKey) *Frame {
+	values := make([]float64, len(timestamps)*len(keys))
+	for i := range values {
+		values[i] = math.NaN()
+	}
+	return &Frame{timestamps: timestamps, keys: keys, values: values, present: make([]bool, len(values))}
+}
+
+// FromItem creates a single-series frame. Numeric items get one column of
+// raw values, with NaN for null datapoints. Aggregate items get one column
+// per requested aggregate, since the response does not say which aggregates
+// were asked for. String items cannot be framed.
+func FromItem(item *dto.DataPointListItem, aggregates ...dto.Aggregate) (*Frame, error) {
+	series := SeriesName(item)
+	switch item.Kind() {
+	case dto.DatapointKindNumeric, dto.DatapointKindNone:
+		return New(item.Timestamps(), []ColumnKey{{Series: series}}, [][]float64{item.Values()})
+	case dto.DatapointKindAggregate:
+		if len(aggregates) == 0 {
+			return nil, fmt.Errorf("aggregate datapoints of %s need the aggregate names to frame", series)
+		}
+		keys := make([]ColumnKey, len(aggregates))
+		columns := make([][]float64, len(aggregates))
+		for i, aggregate := range aggregates {
+			values, err := item.AggregateValues(aggregate)
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = ColumnKey{Series: series, Aggregate: aggregate}
+			columns[i] = values
+		}
+		return New(item.Timestamps(), keys, columns)
+	default:
+		return nil, fmt.Errorf("cannot frame %s datapoints of %s", item.Kind(), series)
+	}
+}
+
+// FromResponse frames every item of a response and joins them.
+func FromResponse(response *dto.DataPointListResponse, how JoinType, aggregates ...dto.Aggregate) (*Frame, error) {
+	frames := make([]*Frame, 0, len(response.GetItems()))
+	for _, item := range response.GetItems() {
+		f, err := FromItem(item, aggregates...)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, f)
+	}
+	return Join(how, frames...)
+}
+
+// SeriesName identifies a series by its externalId, then its instance id
+// as "space:externalId", then its internal id.
+func SeriesName(item *dto.DataPointListItem) string {
+	switch {
+	case item.GetExternalId() != "":
+		return item.GetExternalId()
+	case item.GetInstanceId() != nil:
+		return item.GetInstanceId().GetSpace() + ":" + item.GetInstanceId().GetExternalId()
+	default:
+		return strconv.FormatInt(item.GetId(), 10)
+	}
+}
+
+// Join combines the columns of frames on their timestamps. Column keys must
+// be unique across the frames.
+func Join(how JoinType, frames ...*Frame) (*Frame, error) {
+	var keys []ColumnKey
+	for _, f := range frames {
+		for _, key := range f.keys {
+			if slices.Contains(keys, key) {
+				return nil, fmt.Errorf("duplicated column %s", key)
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	var timestamps []int64
+	switch how {
+	case OuterJoin:
+		for _, f := range frames {
+			timestamps = append(timestamps, f.timestamps...)
+		}
+		slices.Sort(timestamps)
+		timestamps = slices.Compact(timestamps)
+	case InnerJoin:
+		for i, f := range frames {
+			if i == 0 {
+				timestamps = slices.Clone(f.timestamps)
+				continue
+			}
+			timestamps = intersect(timestamps, f.timestamps)
+		}
+	default:
+		return nil, fmt.Errorf("unknown join type %d", how)
+	}
+
+	joined := newFrame(timestamps, keys)
+	column := 0
+	for _, f := range frames {
+		for c := range f.keys {
+			src, srcPresent := f.column(c), f.presentColumn(c)
+			dst, dstPresent := joined.column(column), joined.presentColumn(column)
+			j := 0
+			for i, ts := range f.timestamps {
+				for j < len(timestamps) && timestamps[j] < ts {
+					j++
+				}
+				if j < len(timestamps) && timestamps[j] == ts {
+					dst[j] = src[i]
+					dstPresent[j] = srcPresent[i]
+				}
+			}
+			column++
+		}
+	}
+	return joined, nil
+}
+
+func intersect(a, b []int64) []int64 {
+	var result []int64
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// Len returns the number of timestamps.
+func (f *Frame) Len() int {
+	return len(f.timestamps)
+}
+
+// Timestamps returns the timestamp index in epoch milliseconds. The slice
+// is shared with the frame and must not be modified.
+func (f *Frame) Timestamps() []int64 {
+	return f.timestamps
+}
+
+// Keys returns the column keys in column order.
+func (f *Frame) Keys() []ColumnKey {
+	return slices.Clone(f.keys)
+}
+
+// Column returns the values of the column with the given key. The slice is
+// shared with the frame.
+func (f *Frame) Column(key ColumnKey) ([]float64, bool) {
+	c := slices.Index(f.keys, key)
+	if c < 0 {
+		return nil, false
+	}
+	return f.column(c), true
+}
+
+func (f *Frame) column(c int) []float64 {
+	n := len(f.timestamps)
+	return f.values[c*n : (c+1)*n : (c+1)*n]
+}
+
+func (f *Frame) presentColumn(c int) []bool {
+	n := len(f.timestamps)
+	return f.present[c*n : (c+1)*n : (c+1)*n]
+}
+
+// Missing reports, for each timestamp, whether the column with the given key
+// has no datapoint there. Null datapoints are NaN in the column but are not
+// missing.
+func (f *Frame) Missing(key ColumnKey) ([]bool, bool) {
+	c := slices.Index(f.keys, key)
+	if c < 0 {
+		return nil, false
+	}
+	missing := make([]bool, len(f.timestamps))
+	for i, present := range f.presentColumn(c) {
+		missing[i] = !present
+	}
+	return missing, true
+}
+
+// Matrix returns the values row by row, one row per timestamp and one entry
+// per column in Keys order.
+func (f *Frame) Matrix() [][]float64 {
+	rows := make([][]float64, len(f.timestamps))
+	for i := range rows {
+		rows[i] = make([]float64, len(f.keys))
+		for c := range f.keys {
+			rows[i][c] = f.values[c*len(f.timestamps)+i]
+		}
+	}
+	return rows
+}
+
+// ForwardFill returns a copy of the frame in which every missing value is
+// replaced with the last non-null value before it in the same column. Null
+// datapoints are kept as NaN, and leading missing values stay NaN.
+func (f *Frame) ForwardFill() *Frame {
+	filled := &Frame{
+		timestamps: f.timestamps,
+		keys:       f.keys,
+		values:     slices.Clone(f.values),
+		present:    slices.Clone(f.present),
+	}
+	for c := range filled.keys {
+		column, present := filled.column(c), filled.presentColumn(c)
+		last, found := 0.0, false
+		for i, value := range column {
+			switch {
+			case !present[i] && found:
+				column[i] = last
+				present[i] = true
+			case present[i] && !math.IsNaN(value):
+				last, found = value, true
+			}
+		}
+	}
+	return filled
+}
+
+// AsOf aligns the frame to a new, strictly increasing timestamp index. Each
+// value is the last non-null value of the column at or before the target
+// timestamp, and is left missing when that value is more than tolerance
+// milliseconds older. A tolerance of zero or less accepts values of any age.
+func (f *Frame) AsOf(timestamps []int64, tolerance int64) (*Frame, error) {
+	for i := 1; i < len(timestamps); i++ {
+		if timestamps[i] <= timestamps[i-1] {
+			return nil, fmt.Errorf("timestamps must be strictly increasing (index %d)", i)
+		}
+	}
+
+	aligned := newFrame(slices.Clone(timestamps), slices.Clone(f.keys))
+	for c := range f.keys {
+		src := f.column(c)
+		dst := aligned.column(c)
+		j := 0
+		found := false
+		var lastValue float64
+		var lastTimestamp int64
+		for i, target := range timestamps {
+			for j < len(f.timestamps) && f.timestamps[j] <= target {
+				if !math.IsNaN(src[j]) {
+					found = true
+					lastValue = src[j]
+					lastTimestamp = f.timestamps[j]
+				}
+				j++
+			}
+			if found && (tolerance <= 0 || target-lastTimestamp <= tolerance) {
+				dst[i] = lastValue
+				aligned.present[c*len(timestamps)+i] = true
+			}
+		}
+	}
+	return aligned, nil
+}
@@ -0,0 +1,204 @@
+package frame
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func numericItem(externalId string, points map[int64]float64, timestamps ...int64) *dto.DataPointListItem {
+	var datapoints []*dto.NumericDatapoint
+	for _, ts := range timestamps {
+		datapoints = append(datapoints, &dto.NumericDatapoint{Timestamp: ts, Value: points[ts]})
+	}
+	return &dto.DataPointListItem{
+		ExternalId: externalId,
+		DatapointType: &dto.DataPointListItem_NumericDatapoints{
+			NumericDatapoints: &dto.NumericDatapoints{Datapoints: datapoints},
+		},
+	}
+}
+
+// equalValues compares float slices, treating NaN as equal to NaN
+func equalValues(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.IsNaN(a[i]) && math.IsNaN(b[i]) {
+			continue
+		}
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func column(t *testing.T, f *Frame, series string) []float64 {
+	t.Helper()
+	values, ok := f.Column(ColumnKey{Series: series})
+	if !ok {
+		t.Fatalf("Column %s not found", series)
+	}
+	return values
+}
+
+func testResponse() *dto.DataPointListResponse {
+	return &dto.DataPointListResponse{Items: []*dto.DataPointListItem{
+		numericItem("a", map[int64]float64{1: 10, 2: 20, 4: 40}, 1, 2, 4),
+		numericItem("b", map[int64]float64{2: 200, 3: 300, 4: 400}, 2, 3, 4),
+	}}
+}
+
+func TestFromResponse_joins(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name       string
+		how        JoinType
+		timestamps []int64
+		a          []float64
+		b          []float64
+	}{
+		{"outer", OuterJoin, []int64{1, 2, 3, 4}, []float64{10, 20, nan, 40}, []float64{nan, 200, 300, 400}},
+		{"inner", InnerJoin, []int64{2, 4}, []float64{20, 40}, []float64{200, 400}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := FromResponse(testResponse(), tt.how)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slices.Equal(f.Timestamps(), tt.timestamps) {
+				t.Errorf("Expected timestamps %v, got %v", tt.timestamps, f.Timestamps())
+			}
+			if got := column(t, f, "a"); !equalValues(got, tt.a) {
+				t.Errorf("Expected a %v, got %v", tt.a, got)
+			}
+			if got := column(t, f, "b"); !equalValues(got, tt.b) {
+				t.Errorf("Expected b %v, got %v", tt.b, got)
+			}
+		})
+	}
+}
+
+func TestFrame_ForwardFill(t *testing.T) {
+	f, err := FromResponse(testResponse(), OuterJoin)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	filled := f.ForwardFill()
+
+	nan := math.NaN()
+	if got := column(t, filled, "a"); !equalValues(got, []float64{10, 20, 20, 40}) {
+		t.Errorf("Unexpected filled a %v", got)
+	}
+	if got := column(t, filled, "b"); !equalValues(got, []float64{nan, 200, 300, 400}) {
+		t.Errorf("Unexpected filled b %v", got)
+	}
+	if got := column(t, f, "a"); !math.IsNaN(got[2]) {
+		t.Errorf("Expected the original frame to be unchanged, got %v", got)
+	}
+}
+
+func TestFrame_ForwardFill_keepsNulls(t *testing.T) {
+	// a reports a null at 2 and has no datapoint at 3
+	a := numericItem("a", map[int64]float64{1: 10, 4: 40}, 1, 2, 4)
+	a.GetNumericDatapoints().Datapoints[1].NullValue = true
+	b := numericItem("b", map[int64]float64{1: 100, 2: 200, 3: 300, 4: 400}, 1, 2, 3, 4)
+	f, err := FromResponse(&dto.DataPointListResponse{Items: []*dto.DataPointListItem{a, b}}, OuterJoin)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	missing, ok := f.Missing(ColumnKey{Series: "a"})
+	if !ok || !slices.Equal(missing, []bool{false, false, true, false}) {
+		t.Errorf("Expected only 3 to be missing, got %v", missing)
+	}
+
+	filled := f.ForwardFill()
+	nan := math.NaN()
+	if got := column(t, filled, "a"); !equalValues(got, []float64{10, nan, 10, 40}) {
+		t.Errorf("Expected the null to be kept and the gap filled, got %v", got)
+	}
+	if missing, _ := filled.Missing(ColumnKey{Series: "a"}); slices.Contains(missing, true) {
+		t.Errorf("Expected nothing missing after filling, got %v", missing)
+	}
+}
+
+func TestFrame_AsOf(t *testing.T) {
+	f, err := FromResponse(testResponse(), OuterJoin)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	nan := math.NaN()
+	tests := []struct {
+		name      string
+		tolerance int64
+		a         []float64
+		b         []float64
+	}{
+		{"unlimited", 0, []float64{nan, 10, 20, 40}, []float64{nan, nan, 300, 400}},
+		{"tolerance", 1, []float64{nan, 10, 20, nan}, []float64{nan, nan, 300, nan}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aligned, err := f.AsOf([]int64{0, 1, 3, 6}, tt.tolerance)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := column(t, aligned, "a"); !equalValues(got, tt.a) {
+				t.Errorf("Expected a %v, got %v", tt.a, got)
+			}
+			if got := column(t, aligned, "b"); !equalValues(got, tt.b) {
+				t.Errorf("Expected b %v, got %v", tt.b, got)
+			}
+		})
+	}
+
+	if _, err := f.AsOf([]int64{2, 1}, 0); err == nil {
+		t.Error("Expected an error for unsorted timestamps")
+	}
+}
+
+func TestFromItem_aggregates(t *testing.T) {
+	item := &dto.DataPointListItem{
+		Id: 42,
+		DatapointType: &dto.DataPointListItem_AggregateDatapoints{
+			AggregateDatapoints: &dto.AggregateDatapoints{Datapoints: []*dto.AggregateDatapoint{
+				{Timestamp: 0, Average: 1.5, Count: 3},
+				{Timestamp: 3600000, Average: 2.5, Count: 4},
+			}},
+		},
+	}
+
+	if _, err := FromItem(item); err == nil {
+		t.Error("Expected an error without aggregate names")
+	}
+
+	f, err := FromItem(item, dto.AggregateAverage, dto.AggregateCount)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	keys := f.Keys()
+	if len(keys) != 2 || keys[0].String() != "42|average" || keys[1].String() != "42|count" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+	matrix := f.Matrix()
+	if len(matrix) != 2 || !equalValues(matrix[1], []float64{2.5, 4}) {
+		t.Errorf("Unexpected matrix %v", matrix)
+	}
+}
+
+func TestJoin_duplicatedColumns(t *testing.T) {
+	a, _ := FromItem(numericItem("a", nil, 1))
+	if _, err := Join(OuterJoin, a, a); err == nil {
+		t.Error("Expected an error for duplicated columns")
+	}
+}
@@ -0,0 +1,93 @@
+package status
+
+// codeNames holds the OPC UA names of the codes commonly seen in CDF. The
+// values include the severity bits and the sub-code.
+var codeNames = map[Code]string{
+	Good:      "Good",
+	Uncertain: "Uncertain",
+	Bad:       "Bad",
+
+	0x002D0000: "GoodSubscriptionTransferred",
+	0x002E0000: "GoodCompletesAsynchronously",
+	0x002F0000: "GoodOverload",
+	0x00300000: "GoodClamped",
+	0x00960000: "GoodLocalOverride",
+	0x00A20000: "GoodEntryInserted",
+	0x00A30000: "GoodEntryReplaced",
+	0x00A50000: "GoodNoData",
+	0x00A60000: "GoodMoreData",
+	0x00A70000: "GoodCommunicationEvent",
+	0x00A80000: "GoodShutdownEvent",
+	0x00A90000: "GoodCallAgain",
+	0x00AA0000: "GoodNonCriticalTimeout",
+	0x00BA0000: "GoodResultsMayBeIncomplete",
+	0x00D90000: "GoodDataIgnored",
+
+	0x406C0000: "UncertainReferenceOutOfServer",
+	0x408F0000: "UncertainNoCommunicationLastUsableValue",
+	0x40900000: "UncertainLastUsableValue",
+	0x40910000: "UncertainSubstituteValue",
+	0x40920000: "UncertainInitialValue",
+	0x40930000: "UncertainSensorNotAccurate",
+	0x40940000: "UncertainEngineeringUnitsExceeded",
+	0x40950000: "UncertainSubNormal",
+	0x40A40000: "UncertainDataSubNormal",
+	0x40C00000: "UncertainNotAllNodesAvailable",
+	0x40DE0000: "UncertainDominantValueChanged",
+	0x40E20000: "UncertainDependentValueChanged",
+
+	0x80010000: "BadUnexpectedError",
+	0x80020000: "BadInternalError",
+	0x80030000: "BadOutOfMemory",
+	0x80040000: "BadResourceUnavailable",
+	0x80050000: "BadCommunicationError",
+	0x80060000: "BadEncodingError",
+	0x80070000: "BadDecodingError",
+	0x80080000: "BadEncodingLimitsExceeded",
+	0x80090000: "BadUnknownResponse",
+	0x800A0000: "BadTimeout",
+	0x800B0000: "BadServiceUnsupported",
+	0x800C0000: "BadShutdown",
+	0x800D0000: "BadServerNotConnected",
+	0x800E0000: "BadServerHalted",
+	0x800F0000: "BadNothingToDo",
+	0x80100000: "BadTooManyOperations",
+	0x80310000: "BadNoCommunication",
+	0x80320000: "BadWaitingForInitialData",
+	0x80330000: "BadNodeIdInvalid",
+	0x80340000: "BadNodeIdUnknown",
+	0x803A0000: "BadNotReadable",
+	0x803B0000: "BadNotWritable",
+	0x803C0000: "BadOutOfRange",
+	0x803D0000: "BadNotSupported",
+	0x803E0000: "BadNotFound",
+	0x803F0000: "BadObjectDeleted",
+	0x80740000: "BadTypeMismatch",
+	0x80890000: "BadConfigurationError",
+	0x808A0000: "BadNotConnected",
+	0x808B0000: "BadDeviceFailure",
+	0x808C0000: "BadSensorFailure",
+	0x808D0000: "BadOutOfService",
+	0x809B0000: "BadNoData",
+	0x809D0000: "BadDataLost",
+	0x809E0000: "BadDataUnavailable",
+	0x80D50000: "BadAggregateNotSupported",
+	0x80D60000: "BadAggregateInvalidInputs",
+	0x80D70000: "BadBoundNotFound",
+	0x80D80000: "BadBoundNotSupported",
+	0x80E10000: "BadDominantValueChanged",
+	0x80E30000: "BadDependentValueChanged",
+}
+
+var codesByName = make(map[string]Code, len(codeNames))
+
+var flagsByName = make(map[string]flagName, len(flagNames))
+
+func init() {
+	for code, name := range codeNames {
+		codesByName[name] = code
+	}
+	for _, f := range flagNames {
+		flagsByName[f.name] = f
+	}
+}
@@ -0,0 +1,56 @@
+package status
+
+import "github.com/evertoncolling/poc-requests-go/pkg/dto"
+
+// Of returns the code of a datapoint. Datapoints without a status are Good,
+// and a status that cannot be read counts as Bad.
+func Of(dp *dto.NumericDatapoint) Code {
+	code, err := FromStatus(dp.GetStatus())
+	if err != nil {
+		return Bad
+	}
+	return code
+}
+
+// Filter returns the datapoints whose status is in one of the categories.
+func Filter(datapoints []*dto.NumericDatapoint, categories ...Category) []*dto.NumericDatapoint {
+	var wanted [3]bool
+	for _, category := range categories {
+		if category >= CategoryGood && category <= CategoryBad {
+			wanted[category] = true
+		}
+	}
+
+	var filtered []*dto.NumericDatapoint
+	for _, dp := range datapoints {
+		if wanted[Of(dp).Category()] {
+			filtered = append(filtered, dp)
+		}
+	}
+	return filtered
+}
+
+// Split divides the datapoints by the category of their status, keeping
+// their order.
+func Split(datapoints []*dto.NumericDatapoint) (good, uncertain, bad []*dto.NumericDatapoint) {
+	for _, dp := range datapoints {
+		switch Of(dp).Category() {
+		case CategoryGood:
+			good = append(good, dp)
+		case CategoryUncertain:
+			uncertain = append(uncertain, dp)
+		default:
+			bad = append(bad, dp)
+		}
+	}
+	return good, uncertain, bad
+}
+
+// Count returns the number of datapoints in each category.
+func Count(datapoints []*dto.NumericDatapoint) map[Category]int {
+	counts := make(map[Category]int, 3)
+	for _, dp := range datapoints {
+		counts[Of(dp).Category()]++
+	}
+	return counts
+}
@@ -0,0 +1,233 @@
+// Package status decodes and encodes the OPC UA style status codes that CDF
+// attaches to datapoints when includeStatus is set.
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+// Code is a 32-bit status code. The top two bits hold the category, bits
+// 16-27 the sub-code and the low 16 bits the flags.
+type Code uint32
+
+// Category is the severity of a status code.
+type Category int
+
+const (
+	CategoryGood Category = iota
+	CategoryUncertain
+	CategoryBad
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategoryGood:
+		return "Good"
+	case CategoryUncertain:
+		return "Uncertain"
+	case CategoryBad:
+		return "Bad"
+	default:
+		return fmt.Sprintf("Category(%d)", int(c))
+	}
+}
+
+// The plain category codes.
+const (
+	Good      Code = 0x00000000
+	Uncertain Code = 0x40000000
+	Bad       Code = 0x80000000
+)
+
+// Flags that can be combined with a code.
+const (
+	StructureChanged Code = 1 << 15
+	SemanticsChanged Code = 1 << 14
+	// InfoTypeDataValue marks the limit, overflow and historian bits as
+	// used. Parse sets it whenever one of them is present.
+	InfoTypeDataValue Code = 1 << 10
+	LimitLow          Code = 1 << 8
+	LimitHigh         Code = 2 << 8
+	LimitConstant     Code = 3 << 8
+	Overflow          Code = 1 << 7
+	Calculated        Code = 1
+	Interpolated      Code = 2
+	Partial           Code = 1 << 2
+	ExtraData         Code = 1 << 3
+	MultiValue        Code = 1 << 4
+)
+
+const (
+	severityMask Code = 0xC0000000
+	reservedMask Code = 0x30000000 | 0x3000 | 0x60
+	baseMask     Code = 0xFFFF0000
+	infoTypeMask Code = 0x0C00
+	infoBitsMask Code = 0x03FF
+	limitMask    Code = 0x0300
+	sourceMask   Code = 0x0003
+)
+
+type flagName struct {
+	flag Code
+	mask Code
+	name string
+}
+
+// flagNames lists the flags in the order they appear in a symbol.
+var flagNames = []flagName{
+	{StructureChanged, StructureChanged, "StructureChanged"},
+	{SemanticsChanged, SemanticsChanged, "SemanticsChanged"},
+	{LimitLow, limitMask, "Low"},
+	{LimitHigh, limitMask, "High"},
+	{LimitConstant, limitMask, "Constant"},
+	{Overflow, Overflow, "Overflow"},
+	{Calculated, sourceMask, "Calculated"},
+	{Interpolated, sourceMask, "Interpolated"},
+	{Partial, Partial, "Partial"},
+	{ExtraData, ExtraData, "ExtraData"},
+	{MultiValue, MultiValue, "MultiValue"},
+}
+
+// Category returns the severity of the code.
+func (c Code) Category() Category {
+	return Category(c >> 30)
+}
+
+// IsGood reports whether the code is in the good category.
+func (c Code) IsGood() bool { return c.Category() == CategoryGood }
+
+// IsUncertain reports whether the code is in the uncertain category.
+func (c Code) IsUncertain() bool { return c.Category() == CategoryUncertain }
+
+// IsBad reports whether the code is in the bad category.
+func (c Code) IsBad() bool { return c.Category() == CategoryBad }
+
+// SubCode returns the 12-bit sub-code, such as 0x30 for GoodClamped.
+func (c Code) SubCode() uint16 {
+	return uint16(c>>16) & 0x0FFF
+}
+
+// Base returns the code without its flags, such as BadSensorFailure for
+// "BadSensorFailure, Overflow".
+func (c Code) Base() Code {
+	return c & baseMask
+}
+
+// Flags returns the flag bits of the code.
+func (c Code) Flags() Code {
+	return c &^ baseMask
+}
+
+// Limit returns LimitLow, LimitHigh, LimitConstant or 0.
+func (c Code) Limit() Code {
+	if c&infoTypeMask != InfoTypeDataValue {
+		return 0
+	}
+	return c & limitMask
+}
+
+// Has reports whether the code carries flag. Limit and historian flags are
+// compared as a whole field, so LimitConstant does not imply LimitLow.
+func (c Code) Has(flag Code) bool {
+	for _, f := range flagNames {
+		if f.flag == flag {
+			if f.mask&infoBitsMask != 0 && c&infoTypeMask != InfoTypeDataValue {
+				return false
+			}
+			return c&f.mask == flag
+		}
+	}
+	return flag != 0 && c&flag == flag
+}
+
+// Validate checks that the code only uses bits CDF accepts.
+func (c Code) Validate() error {
+	switch {
+	case c&severityMask == severityMask:
+		return fmt.Errorf("invalid status code %#08x: reserved severity", uint32(c))
+	case c&reservedMask != 0:
+		return fmt.Errorf("invalid status code %#08x: reserved bits are set", uint32(c))
+	case c&infoTypeMask != 0 && c&infoTypeMask != InfoTypeDataValue:
+		return fmt.Errorf("invalid status code %#08x: unknown info type", uint32(c))
+	case c&infoTypeMask == 0 && c&infoBitsMask != 0:
+		return fmt.Errorf("invalid status code %#08x: info bits are set without the data value info type", uint32(c))
+	case c&sourceMask == sourceMask:
+		return fmt.Errorf("invalid status code %#08x: reserved historian bits", uint32(c))
+	}
+	return nil
+}
+
+// Symbol returns the symbol CDF uses for the code, such as "Good" or
+// "BadSensorFailure, StructureChanged, High". Sub-codes without a known name
+// fall back to the category name.
+func (c Code) Symbol() string {
+	name, ok := codeNames[c.Base()]
+	if !ok {
+		name = c.Category().String()
+	}
+	parts := []string{name}
+	for _, f := range flagNames {
+		if c.Has(f.flag) {
+			parts = append(parts, f.name)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c Code) String() string {
+	return c.Symbol()
+}
+
+// Parse parses a symbol such as "Uncertain, Low" into a code. The first part
+// names the code and the rest are flags.
+func Parse(symbol string) (Code, error) {
+	parts := strings.Split(symbol, ",")
+	name := strings.TrimSpace(parts[0])
+	code, ok := codesByName[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown status code %q", name)
+	}
+
+	for _, part := range parts[1:] {
+		flag, ok := flagsByName[strings.TrimSpace(part)]
+		if !ok {
+			return 0, fmt.Errorf("unknown status flag %q in %q", strings.TrimSpace(part), symbol)
+		}
+		if code&flag.mask != 0 && code&flag.mask != flag.flag {
+			return 0, fmt.Errorf("conflicting status flags in %q", symbol)
+		}
+		if flag.mask&infoBitsMask != 0 {
+			code |= InfoTypeDataValue
+		}
+		code |= flag.flag
+	}
+	return code, nil
+}
+
+// FromStatus returns the code of a datapoint status. A nil status is Good,
+// since CDF leaves out the status of good datapoints. The code is used when
+// set, otherwise the symbol is parsed.
+func FromStatus(status *dto.Status) (Code, error) {
+	if status.GetCode() == 0 {
+		if status.GetSymbol() == "" {
+			return Good, nil
+		}
+		return Parse(status.GetSymbol())
+	}
+	if status.GetCode() < 0 || status.GetCode() > 0xFFFFFFFF {
+		return 0, fmt.Errorf("status code %d is out of range", status.GetCode())
+	}
+	code := Code(status.GetCode())
+	if err := code.Validate(); err != nil {
+		return 0, err
+	}
+	return code, nil
+}
+
+// ToStatus returns a datapoint status with both the code and the symbol set.
+func ToStatus(code Code) *dto.Status {
+	return &dto.Status{Code: int64(code), Symbol: code.Symbol()}
+}
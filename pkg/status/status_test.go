@@ -0,0 +1,168 @@
+package status
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/evertoncolling/poc-requests-go/pkg/dto"
+)
+
+func TestCode_Symbol(t *testing.T) {
+	tests := []struct {
+		code     Code
+		expected string
+	}{
+		{Good, "Good"},
+		{Bad, "Bad"},
+		{0x00300000, "GoodClamped"},
+		{0x808C0000 | StructureChanged | InfoTypeDataValue | LimitHigh, "BadSensorFailure, StructureChanged, High"},
+		{Uncertain | InfoTypeDataValue | LimitConstant | Overflow | Interpolated, "Uncertain, Constant, Overflow, Interpolated"},
+		// Info bits are ignored without the data value info type
+		{Good | LimitLow, "Good"},
+		// Unnamed sub-codes fall back to the category
+		{0x80FF0000, "Bad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if result := tt.code.Symbol(); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		symbol      string
+		expected    Code
+		expectedErr bool
+	}{
+		{symbol: "Good", expected: Good},
+		{symbol: "BadSensorFailure", expected: 0x808C0000},
+		{symbol: "Uncertain, Low", expected: Uncertain | InfoTypeDataValue | LimitLow},
+		{symbol: "Bad,StructureChanged,Calculated,ExtraData", expected: Bad | StructureChanged | InfoTypeDataValue | Calculated | ExtraData},
+		{symbol: "GoodClamped, SemanticsChanged", expected: 0x00300000 | SemanticsChanged},
+		{symbol: "Bogus", expectedErr: true},
+		{symbol: "Good, Sideways", expectedErr: true},
+		{symbol: "Good, Low, High", expectedErr: true},
+		{symbol: "", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			code, err := Parse(tt.symbol)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %#08x", uint32(code))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if code != tt.expected {
+				t.Errorf("Expected %#08x, got %#08x", uint32(tt.expected), uint32(code))
+			}
+			if symbol := code.Symbol(); symbol != tt.symbol {
+				if reparsed, _ := Parse(symbol); reparsed != code {
+					t.Errorf("Expected %q to round-trip, got %q", tt.symbol, symbol)
+				}
+			}
+		})
+	}
+}
+
+func TestCode_accessors(t *testing.T) {
+	code := Code(0x40930000) | InfoTypeDataValue | LimitConstant | Overflow | Partial
+
+	if code.Category() != CategoryUncertain || !code.IsUncertain() {
+		t.Errorf("Expected uncertain, got %s", code.Category())
+	}
+	if code.SubCode() != 0x93 {
+		t.Errorf("Expected sub-code 0x93, got %#x", code.SubCode())
+	}
+	if code.Base() != 0x40930000 {
+		t.Errorf("Expected base 0x40930000, got %#08x", uint32(code.Base()))
+	}
+	if code.Limit() != LimitConstant {
+		t.Errorf("Expected LimitConstant, got %#x", uint32(code.Limit()))
+	}
+	if !code.Has(Overflow) || !code.Has(Partial) || !code.Has(LimitConstant) {
+		t.Error("Expected Overflow, Partial and LimitConstant to be set")
+	}
+	if code.Has(LimitLow) || code.Has(Calculated) || code.Has(StructureChanged) {
+		t.Error("Expected LimitLow, Calculated and StructureChanged to be unset")
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      *dto.Status
+		expected    Code
+		expectedErr bool
+	}{
+		{name: "nil", status: nil, expected: Good},
+		{name: "code", status: &dto.Status{Code: 2147483648, Symbol: "Bad"}, expected: Bad},
+		{name: "unknown symbol with code", status: &dto.Status{Code: 0x80FF0000, Symbol: "BadSomethingNew"}, expected: 0x80FF0000},
+		{name: "symbol only", status: &dto.Status{Symbol: "Uncertain, High"}, expected: Uncertain | InfoTypeDataValue | LimitHigh},
+		{name: "negative", status: &dto.Status{Code: -1}, expectedErr: true},
+		{name: "reserved severity", status: &dto.Status{Code: 0xC0000000}, expectedErr: true},
+		{name: "info bits without info type", status: &dto.Status{Code: int64(Bad | Overflow)}, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := FromStatus(tt.status)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %#08x", uint32(code))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if code != tt.expected {
+				t.Errorf("Expected %#08x, got %#08x", uint32(tt.expected), uint32(code))
+			}
+		})
+	}
+
+	status := ToStatus(0x808C0000 | StructureChanged)
+	if status.Code != 0x808C8000 || status.Symbol != "BadSensorFailure, StructureChanged" {
+		t.Errorf("Expected BadSensorFailure, StructureChanged, got %d %q", status.Code, status.Symbol)
+	}
+}
+
+func TestFilterAndSplit(t *testing.T) {
+	datapoints := []*dto.NumericDatapoint{
+		{Timestamp: 0, Value: 1},
+		{Timestamp: 1, Value: 2, Status: &dto.Status{Code: int64(Uncertain), Symbol: "Uncertain"}},
+		{Timestamp: 2, NullValue: true, Status: &dto.Status{Code: 0x808C0000, Symbol: "BadSensorFailure"}},
+		{Timestamp: 3, Value: 4, Status: &dto.Status{Symbol: "GoodClamped"}},
+		{Timestamp: 4, Value: 5, Status: &dto.Status{Symbol: "Unreadable"}},
+	}
+
+	timestamps := func(dps []*dto.NumericDatapoint) []int64 {
+		var result []int64
+		for _, dp := range dps {
+			result = append(result, dp.GetTimestamp())
+		}
+		return result
+	}
+	if result := timestamps(Filter(datapoints, CategoryGood, CategoryUncertain)); !slices.Equal(result, []int64{0, 1, 3}) {
+		t.Errorf("Expected [0 1 3], got %v", result)
+	}
+
+	good, uncertain, bad := Split(datapoints)
+	if !slices.Equal(timestamps(good), []int64{0, 3}) || !slices.Equal(timestamps(uncertain), []int64{1}) || !slices.Equal(timestamps(bad), []int64{2, 4}) {
+		t.Errorf("Unexpected split: %v %v %v", timestamps(good), timestamps(uncertain), timestamps(bad))
+	}
+
+	counts := Count(datapoints)
+	if counts[CategoryGood] != 2 || counts[CategoryUncertain] != 1 || counts[CategoryBad] != 2 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+}